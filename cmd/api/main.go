@@ -9,13 +9,18 @@ import (
 
 	"gofiberobservability/internal/handler"
 	"gofiberobservability/internal/middleware"
+	"gofiberobservability/internal/repository"
+	"gofiberobservability/pkg/cache"
 	"gofiberobservability/pkg/config"
 	"gofiberobservability/pkg/database"
 	"gofiberobservability/pkg/logger"
 	"gofiberobservability/pkg/metrics"
+	"gofiberobservability/pkg/slo"
 	"gofiberobservability/pkg/tracer"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
@@ -43,8 +48,13 @@ func main() {
 	}
 	defer metrics.Shutdown(context.Background(), log)
 
-	// Initialize PostgreSQL database
-	dbCtx, dbCancel := context.WithTimeout(context.Background(), 15*time.Second)
+	// Initialize SLO tracking (burn rates and error budgets from SLO_OBJECTIVES_FILE)
+	if err := slo.InitSLO(cfg, log); err != nil {
+		log.Fatal("Failed to initialize SLOs", zap.Error(err))
+	}
+
+	// Initialize PostgreSQL (retrying while it starts up), or SQLite for a sqlite:// DATABASE_URL
+	dbCtx, dbCancel := context.WithTimeout(context.Background(), cfg.StartupRetryTimeout)
 	defer dbCancel()
 
 	if err := database.InitDatabase(dbCtx, cfg, log); err != nil {
@@ -52,7 +62,7 @@ func main() {
 	}
 	defer database.Close(log)
 
-	// Initialize Redis
+	// Initialize Redis (optional in SQLite mode)
 	if err := database.InitRedis(dbCtx, cfg, log); err != nil {
 		log.Fatal("Failed to initialize Redis", zap.Error(err))
 	}
@@ -85,15 +95,47 @@ func main() {
 		},
 	})
 
+	// Connection-level saturation gauges (open connections, worker concurrency)
+	if err := middleware.RegisterServerMetrics(app); err != nil {
+		log.Error("Failed to register server metrics", zap.Error(err))
+	}
+
 	// Register middleware (order matters!)
 	app.Use(middleware.RecoveryMiddleware(log))
 
+	// Keep health probes and favicon requests out of traces, logs and metrics
+	filter, err := middleware.NewFilter(middleware.FilterRule{
+		Paths:     cfg.HTTPExcludePaths,
+		PathRegex: cfg.HTTPExcludePathRegex,
+	}, middleware.FilterRule{
+		Methods: cfg.HTTPExcludeMethods,
+	})
+	if err != nil {
+		log.Fatal("Invalid HTTP telemetry filter", zap.Error(err))
+	}
+
+	middlewareOpts := []middleware.Option{
+		middleware.WithSemconvStabilityOptIn(cfg.SemconvStabilityOptIn),
+		middleware.WithFilter(filter),
+		middleware.WithBaggageMetricKeys(cfg.BaggageMetricKeys...),
+		middleware.WithUnmatchedRouteLabel(cfg.HTTPMetricsUnmatchedRoute),
+		middleware.WithStatusClasses(cfg.HTTPMetricsStatusClasses),
+		middleware.WithAttributeValueLimit(cfg.HTTPMetricsAttributeValueLimit),
+		middleware.WithSLO(slo.GetTracker()),
+	}
+
 	// Add tracing middleware if tracing is enabled
 	if cfg.TracingEnabled {
-		app.Use(middleware.TracingMiddleware(cfg.ServiceName))
+		app.Use(middleware.TracingMiddleware(cfg.ServiceName, middlewareOpts...))
 	}
 
-	app.Use(middleware.LoggingMiddleware())
+	app.Use(middleware.LoggingMiddleware(middlewareOpts...))
+
+	// Reads that follow a write in the same request go to the primary
+	app.Use(func(c fiber.Ctx) error {
+		c.SetContext(database.WithReadYourWrites(c.Context()))
+		return c.Next()
+	})
 
 	// Favicon handler to stay silent in logs
 	app.Get("/favicon.ico", func(c fiber.Ctx) error {
@@ -120,13 +162,67 @@ func main() {
 	})
 
 	// Health check
-	app.Get("/health", handler.HealthCheck())
+	app.Get("/health", handler.HealthCheck(cfg.AdminToken))
+
+	// Operator endpoints live on a separate admin listener when ADMIN_ADDR is set;
+	// on the public listener they need ADMIN_TOKEN
+	admin := app
+	adminAuth := middleware.AdminAuth(cfg.AdminToken)
+	if cfg.AdminAddr != "" {
+		admin = fiber.New(fiber.Config{AppName: cfg.ServiceName + "-admin"})
+		adminAuth = func(c fiber.Ctx) error { return c.Next() }
+	}
+
+	// Prometheus scrape endpoint (OpenMetrics with exemplars)
+	if h := metrics.PrometheusHandler(); h != nil {
+		admin.Get("/metrics", adminAuth, adaptor.HTTPHandler(h))
+	}
 
-	// User CRUD (backed by PostgreSQL)
-	app.Get("/api/users", handler.ListUsers(cfg.ServiceName))
-	app.Post("/api/users", handler.CreateUser(cfg.ServiceName))
-	app.Get("/api/users/:id", handler.GetUser(cfg.ServiceName))
-	app.Delete("/api/users/:id", handler.DeleteUser(cfg.ServiceName))
+	// SLO status: objectives, burn rates and firing alerts for this instance
+	admin.Get("/debug/slo", adminAuth, handler.SLOStatus())
+
+	// User CRUD (backed by PostgreSQL, or SQLite for local development)
+	var users repository.UserRepository
+	if db := database.SQLite(); db != nil {
+		users = repository.NewSQLiteUserRepository(db)
+	} else {
+		users = repository.NewReplicatedUserRepository(database.Primary())
+	}
+	// Cache-aside for GetUser; without Redis, lookups still coalesce but aren't stored.
+	// Mutations invalidate across instances over Redis pub/sub.
+	var (
+		rdb redis.Cmdable
+		bus *cache.Bus
+	)
+	if client := database.GetRedis(); client != nil {
+		rdb = client
+		bus = cache.NewBus(client, cfg.CacheInvalidationChannel)
+	}
+	codec, ok := cache.CodecByName(cfg.CacheCodec)
+	if !ok {
+		log.Fatal("Unknown cache codec", zap.String("codec", cfg.CacheCodec))
+	}
+	userCache := cache.New("users", rdb, cache.Options{
+		TTL:           cfg.UserCacheTTL,
+		Jitter:        cfg.CacheTTLJitter,
+		StaleTTL:      cfg.UserCacheStaleTTL,
+		Codec:         codec,
+		NotFound:      repository.ErrNotFound,
+		Bus:           bus,
+		RedeleteDelay: cfg.CacheRedeleteDelay,
+	})
+	defer userCache.Close()
+	if bus != nil {
+		if err := bus.Start(context.Background(), log); err != nil {
+			log.Fatal("Failed to start cache invalidation bus", zap.Error(err))
+		}
+		defer bus.Close()
+	}
+	app.Get("/api/users", handler.ListUsers(users))
+	app.Post("/api/users", handler.CreateUser(users))
+	app.Get("/api/users/:id", handler.GetUser(users, userCache))
+	app.Put("/api/users/:id", handler.UpdateUser(users, userCache))
+	app.Delete("/api/users/:id", handler.DeleteUser(users, userCache))
 
 	// Error simulation endpoint
 	app.Get("/api/error", func(c fiber.Ctx) error {
@@ -153,6 +249,15 @@ func main() {
 		}
 	}()
 
+	if admin != app {
+		go func() {
+			log.Info("Starting admin server", zap.String("addr", cfg.AdminAddr))
+			if err := admin.Listen(cfg.AdminAddr, fiber.ListenConfig{DisableStartupMessage: true}); err != nil {
+				log.Error("Admin server error", zap.Error(err))
+			}
+		}()
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -173,6 +278,12 @@ func main() {
 		log.Error("Server shutdown error", zap.Error(err))
 	}
 
+	if admin != app {
+		if err := admin.ShutdownWithContext(shutdownCtx); err != nil {
+			log.Error("Admin server shutdown error", zap.Error(err))
+		}
+	}
+
 	log.Info("Server shutdown complete")
 
 	// Logger, tracer, metrics, and database will be shut down by defer statements
@@ -0,0 +1,136 @@
+// Command migrate manages the database schema outside of service startup.
+//
+//	migrate [-target N] [-dry-run] up     apply pending migrations (up to N)
+//	migrate [-target N] [-dry-run] down   revert migrations newer than N (default: the latest one)
+//	migrate status                        list migrations and whether they are applied
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"gofiberobservability/pkg/config"
+	"gofiberobservability/pkg/database"
+	"gofiberobservability/pkg/logger"
+	"gofiberobservability/pkg/tracer"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	target := flag.Int64("target", -1, "version to migrate to (up: default latest, down: default previous)")
+	dryRun := flag.Bool("dry-run", false, "log the plan and SQL without executing it")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: migrate [-target N] [-dry-run] up|down|status")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.NewConfig()
+	if err := logger.InitLogger(cfg); err != nil {
+		panic("Failed to initialize logger: " + err.Error())
+	}
+	defer logger.Shutdown(context.Background())
+	log := logger.GetLogger()
+
+	// Migrations are traced like requests, so a slow or failed one shows up in Tempo
+	if err := tracer.InitTracer(cfg, log); err != nil {
+		log.Fatal("Failed to initialize tracer", zap.Error(err))
+	}
+	defer tracer.Shutdown(context.Background(), log)
+
+	// SQLite databases are migrated by the service at startup
+	if cfg.UsesSQLite() {
+		log.Fatal("The migrate command supports PostgreSQL only; SQLite databases are migrated when the service starts")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, cfg.StartupRetryTimeout)
+	defer cancel()
+	if err := database.InitDatabase(connectCtx, cfg, log); err != nil {
+		log.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer database.Close(log)
+
+	migrator, err := database.NewMigrator(database.GetPool(), log)
+	if err != nil {
+		log.Fatal("Failed to load migrations", zap.Error(err))
+	}
+
+	switch cmd := flag.Arg(0); cmd {
+	case "up":
+		err = migrator.Up(ctx, database.MigrateOptions{Target: max(*target, 0), DryRun: *dryRun})
+	case "down":
+		opts := database.MigrateOptions{Target: *target, DryRun: *dryRun}
+		if opts.Target < 0 {
+			opts.Target, err = previousVersion(ctx, migrator)
+		}
+		if err == nil {
+			err = migrator.Down(ctx, opts)
+		}
+	case "status":
+		err = printStatus(ctx, migrator)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Error("Migration command failed", zap.Error(err))
+		os.Exit(1)
+	}
+}
+
+// previousVersion is the version below the newest applied one, so a bare
+// "down" reverts a single migration.
+func previousVersion(ctx context.Context, m *database.Migrator) (int64, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var applied []int64
+	for _, s := range statuses {
+		if s.Applied {
+			applied = append(applied, s.Version)
+		}
+	}
+	if len(applied) < 2 {
+		return 0, nil
+	}
+	return applied[len(applied)-2], nil
+}
+
+func printStatus(ctx context.Context, m *database.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.Applied {
+			state, appliedAt = "applied", s.AppliedAt.Format(time.RFC3339)
+		}
+		switch {
+		case s.Modified:
+			state += " (modified)"
+		case s.Unknown:
+			state += " (unknown to this build)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,321 @@
+// Command rulesgen generates the Prometheus recording and alerting rules for
+// the service from the instrument names it records and the declared SLOs.
+//
+//	go run ./cmd/rulesgen                # rewrite prometheus-rules.yaml
+//	go run ./cmd/rulesgen -check         # fail if prometheus-rules.yaml is stale
+//
+// The committed prometheus-rules.yaml is the golden file: go test ./cmd/rulesgen
+// (or -check) fails when a renamed instrument or changed objective drifts from
+// it, and go test ./cmd/rulesgen -update rewrites it.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"gofiberobservability/internal/middleware"
+	"gofiberobservability/pkg/slo"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	header = "# Code generated by cmd/rulesgen; DO NOT EDIT.\n"
+
+	defaultService = "gofiberobservability"
+	defaultRunbook = "https://runbooks.example.com/{{.Service}}/{{.Alert}}"
+)
+
+func main() {
+	sloFile := flag.String("slo", "slo.example.json", "JSON file with SLO objectives")
+	output := flag.String("o", "prometheus-rules.yaml", "rules file to write or check")
+	service := flag.String("service", defaultService, "service_name label the rules select on")
+	runbook := flag.String("runbook", defaultRunbook,
+		"runbook_url template; fields: .Service .Alert .SLO .Severity")
+	check := flag.Bool("check", false, "compare against the existing output instead of writing it")
+	flag.Parse()
+
+	out, err := render(*sloFile, *service, *runbook)
+	if err != nil {
+		fail(err)
+	}
+
+	if *check {
+		current, err := os.ReadFile(*output)
+		if err != nil {
+			fail(err)
+		}
+		if !bytes.Equal(current, out) {
+			fail(fmt.Errorf("%s is out of date (first difference at line %d); run: go run ./cmd/rulesgen",
+				*output, firstDiffLine(current, out)))
+		}
+		return
+	}
+
+	if err := os.WriteFile(*output, out, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+// render builds the rules file for the objectives in sloFile.
+func render(sloFile, service, runbook string) ([]byte, error) {
+	objectives, err := slo.LoadObjectives(sloFile)
+	if err != nil {
+		return nil, err
+	}
+	runbookTmpl, err := template.New("runbook").Parse(runbook)
+	if err != nil {
+		return nil, fmt.Errorf("invalid runbook template: %w", err)
+	}
+
+	g := &generator{service: service, runbook: runbookTmpl}
+	rules, err := g.rules(objectives)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(rules); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "rulesgen:", err)
+	os.Exit(1)
+}
+
+type ruleFile struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []rule `yaml:"rules"`
+}
+
+type rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type generator struct {
+	service string
+	runbook *template.Template
+}
+
+func (g *generator) rules(objectives []slo.Objective) (ruleFile, error) {
+	groups := []ruleGroup{g.httpRules()}
+
+	if len(objectives) > 0 {
+		sloRules := g.sloRecordingRules()
+		for _, o := range objectives {
+			alerts, err := g.sloAlerts(o)
+			if err != nil {
+				return ruleFile{}, err
+			}
+			sloRules = append(sloRules, alerts...)
+		}
+		groups = append(groups, ruleGroup{Name: g.service + ".slo", Rules: sloRules})
+	}
+
+	telemetry, err := g.telemetryRules()
+	if err != nil {
+		return ruleFile{}, err
+	}
+	groups = append(groups, telemetry)
+
+	return ruleFile{Groups: groups}, nil
+}
+
+// httpRules are the golden-signal aggregations the dashboards query.
+func (g *generator) httpRules() ruleGroup {
+	duration := promName(middleware.RequestDurationMetric, "s")
+	active := promName(middleware.ActiveRequestsMetric, "")
+	sel := g.selector()
+	by := "service_name, http_route, http_request_method"
+
+	rules := []rule{
+		{
+			Record: "http_route:http_server_requests:rate5m",
+			Expr:   fmt.Sprintf("sum by (%s) (rate(%s_count%s[5m]))", by, duration, sel),
+		},
+		{
+			Record: "http_route:http_server_errors:rate5m",
+			Expr:   fmt.Sprintf("sum by (%s) (rate(%s_count%s[5m]))", by, duration, g.selector(`http_response_status_code=~"5.."`)),
+		},
+		{
+			Record: "http_route:http_server_error_ratio:rate5m",
+			Expr:   "http_route:http_server_errors:rate5m / http_route:http_server_requests:rate5m",
+		},
+	}
+	for _, p := range []int{50, 95, 99} {
+		rules = append(rules, rule{
+			Record: fmt.Sprintf("http_route:%s:p%d_5m", duration, p),
+			Expr: fmt.Sprintf("histogram_quantile(%s, sum by (le, service_name, http_route) (rate(%s_bucket%s[5m])))",
+				formatFloat(float64(p)/100), duration, sel),
+		})
+	}
+	rules = append(rules, rule{
+		Record: "service:" + active + ":sum",
+		Expr:   fmt.Sprintf("sum by (service_name) (%s%s)", active, sel),
+	})
+
+	return ruleGroup{Name: g.service + ".http", Rules: rules}
+}
+
+// sloRecordingRules record the bad-event ratio of every SLO over each burn-rate window.
+func (g *generator) sloRecordingRules() []rule {
+	events := promName(slo.EventsMetric, "") + "_total"
+	rules := make([]rule, 0, len(slo.BurnRateWindows))
+	for _, w := range slo.BurnRateWindows {
+		window := slo.FormatDuration(w)
+		rules = append(rules, rule{
+			Record: "slo:sli_error:ratio_rate" + window,
+			Expr: fmt.Sprintf("sum by (service_name, slo_name) (rate(%s%s[%s]))\n/\nsum by (service_name, slo_name) (rate(%s%s[%s]))",
+				events, g.selector(`outcome="bad"`), window, events, g.selector(), window),
+		})
+	}
+	return rules
+}
+
+// sloAlerts emits one multi-window burn-rate alert per severity for o.
+func (g *generator) sloAlerts(o slo.Objective) ([]rule, error) {
+	name := camel(o.Name) + "ErrorBudgetBurn"
+	sel := g.selector(fmt.Sprintf("slo_name=%q", o.Name))
+
+	var rules []rule
+	for _, severity := range []string{"page", "ticket"} {
+		var conditions []string
+		for _, a := range slo.BurnRateAlerts {
+			if a.Severity != severity {
+				continue
+			}
+			threshold := fmt.Sprintf("(%s * %s)", formatFloat(a.Threshold(o.Window.Duration)), formatFloat(o.ErrorBudget()))
+			conditions = append(conditions, fmt.Sprintf("(\n  slo:sli_error:ratio_rate%s%s > %s\nand\n  slo:sli_error:ratio_rate%s%s > %s\n)",
+				slo.FormatDuration(a.LongWindow), sel, threshold,
+				slo.FormatDuration(a.ShortWindow), sel, threshold))
+		}
+
+		runbook, err := g.runbookURL(name, o.Name, severity)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule{
+			Alert: name,
+			Expr:  strings.Join(conditions, "\nor\n"),
+			For:   map[string]string{"page": "2m", "ticket": "15m"}[severity],
+			Labels: map[string]string{
+				"severity": severity,
+				"slo_name": o.Name,
+			},
+			Annotations: map[string]string{
+				"summary":     fmt.Sprintf("SLO %s is burning its error budget too fast", o.Name),
+				"description": describe(o),
+				"runbook_url": runbook,
+			},
+		})
+	}
+	return rules, nil
+}
+
+// telemetryRules alert on the telemetry pipeline itself dropping detail.
+func (g *generator) telemetryRules() (ruleGroup, error) {
+	folded := promName(middleware.AttributeFoldedMetric, "") + "_total"
+	const name = "MetricAttributeValuesFolded"
+
+	runbook, err := g.runbookURL(name, "", "ticket")
+	if err != nil {
+		return ruleGroup{}, err
+	}
+	return ruleGroup{Name: g.service + ".telemetry", Rules: []rule{{
+		Alert:  name,
+		Expr:   fmt.Sprintf("sum by (service_name, attribute) (increase(%s%s[15m])) > 0", folded, g.selector()),
+		For:    "15m",
+		Labels: map[string]string{"severity": "ticket"},
+		Annotations: map[string]string{
+			"summary":     "HTTP metric attribute {{ $labels.attribute }} exceeds its value limit",
+			"description": "New values are recorded as \"other\"; raise OTEL_HTTP_METRICS_ATTRIBUTE_VALUE_LIMIT or drop the dimension.",
+			"runbook_url": runbook,
+		},
+	}}}, nil
+}
+
+func (g *generator) selector(matchers ...string) string {
+	return "{" + strings.Join(append([]string{fmt.Sprintf("service_name=%q", g.service)}, matchers...), ",") + "}"
+}
+
+func (g *generator) runbookURL(alert, sloName, severity string) (string, error) {
+	var buf strings.Builder
+	err := g.runbook.Execute(&buf, struct {
+		Service, Alert, SLO, Severity string
+	}{g.service, alert, sloName, severity})
+	return buf.String(), err
+}
+
+// promName mirrors the collector's OTLP → Prometheus name translation for the
+// units used by the instruments referenced here.
+func promName(name, unit string) string {
+	n := strings.ReplaceAll(name, ".", "_")
+	switch unit {
+	case "s":
+		n += "_seconds"
+	case "By":
+		n += "_bytes"
+	}
+	return n
+}
+
+func describe(o slo.Objective) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%% of ", formatFloat(o.Target*100))
+	if o.Method != "" {
+		b.WriteString(o.Method + " ")
+	}
+	b.WriteString(o.Route)
+	if o.Latency.Duration > 0 {
+		fmt.Fprintf(&b, " complete under %s without a 5xx", slo.FormatDuration(o.Latency.Duration))
+	} else {
+		b.WriteString(" succeed without a 5xx")
+	}
+	fmt.Fprintf(&b, " over %s.", slo.FormatDuration(o.Window.Duration))
+	return b.String()
+}
+
+func camel(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' || r == ' ' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// formatFloat prints thresholds without float noise (1-0.999 = 0.0010000000000000009).
+func formatFloat(f float64) string {
+	return fmt.Sprintf("%.6g", f)
+}
+
+func firstDiffLine(a, b []byte) int {
+	la, lb := strings.Split(string(a), "\n"), strings.Split(string(b), "\n")
+	for i := 0; i < min(len(la), len(lb)); i++ {
+		if la[i] != lb[i] {
+			return i + 1
+		}
+	}
+	return min(len(la), len(lb)) + 1
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden prometheus-rules.yaml")
+
+const (
+	goldenSLOFile   = "../../slo.example.json"
+	goldenRulesFile = "../../prometheus-rules.yaml"
+)
+
+// TestGolden fails when the committed rules drift from what the current
+// instrument names and example objectives generate.
+func TestGolden(t *testing.T) {
+	got, err := render(goldenSLOFile, defaultService, defaultRunbook)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	if *update {
+		if err := os.WriteFile(goldenRulesFile, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenRulesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("prometheus-rules.yaml is out of date (first difference at line %d); run: go test ./cmd/rulesgen -update",
+			firstDiffLine(want, got))
+	}
+}
@@ -1,30 +1,55 @@
 package handler
 
 import (
+	"context"
+	"time"
+
+	"gofiberobservability/internal/middleware"
 	"gofiberobservability/pkg/database"
 
 	"github.com/gofiber/fiber/v3"
 )
 
+// healthCheckTimeout bounds all dependency checks of one /health request.
+const healthCheckTimeout = 3 * time.Second
+
 // HealthCheck returns the health status of the application and its dependencies.
-func HealthCheck() fiber.Handler {
+// Pings bypass the circuit breakers, whose states are reported alongside.
+// With ?verbose=1 and the admin token, it adds per-node latency, pool stats,
+// recovery state and replication lag, and Redis role and memory. In SQLite
+// mode the database check is the SQLite file and Redis may be disabled.
+func HealthCheck(adminToken string) fiber.Handler {
 	return func(c fiber.Ctx) error {
-		dbStatus := "up"
-		if err := database.HealthCheck(c.Context()); err != nil {
-			dbStatus = "down"
+		verbose := fiber.Query[bool](c, "verbose")
+		if verbose && !middleware.IsAdmin(c, adminToken) {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="admin"`)
+			return fiber.NewError(fiber.StatusUnauthorized, "Admin token required for verbose health")
 		}
 
-		redisStatus := "up"
-		if err := database.RedisHealthCheck(c.Context()); err != nil {
-			redisStatus = "down"
+		ctx, cancel := context.WithTimeout(c.Context(), healthCheckTimeout)
+		defer cancel()
+
+		var db database.CheckResult
+		checks := fiber.Map{}
+		if database.SQLite() != nil {
+			h := database.SQLiteHealthCheck(ctx, verbose)
+			db, checks["sqlite"] = h.CheckResult, h
+		} else {
+			h := database.HealthCheck(ctx, verbose)
+			db = h.CheckResult
+			if verbose {
+				checks["postgres"] = append([]database.PostgresHealth{h}, database.ReplicaHealthCheck(ctx, true)...)
+			}
 		}
+		redis := database.RedisHealthCheck(ctx, verbose)
+		checks["redis"] = redis
 
 		status := fiber.StatusOK
-		if dbStatus != "up" || redisStatus != "up" {
+		if !db.Up() || !redis.Healthy() {
 			status = fiber.StatusServiceUnavailable
 		}
 
-		return c.Status(status).JSON(fiber.Map{
+		body := fiber.Map{
 			"status": func() string {
 				if status == fiber.StatusOK {
 					return "healthy"
@@ -32,9 +57,15 @@ func HealthCheck() fiber.Handler {
 				return "unhealthy"
 			}(),
 			"dependencies": fiber.Map{
-				"database": dbStatus,
-				"redis":    redisStatus,
+				"database": db.Status,
+				"redis":    redis.Status,
 			},
-		})
+			"circuit_breakers": database.BreakerStates(),
+		}
+		if verbose {
+			body["checks"] = checks
+		}
+
+		return c.Status(status).JSON(body)
 	}
 }
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"gofiberobservability/pkg/metrics"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Lookup outcomes recorded on users.lookups.
+const (
+	lookupHit      = "hit"
+	lookupMiss     = "miss"
+	lookupNotFound = "not_found"
+	lookupError    = "error"
+)
+
+// userMetrics are the business instruments of the user domain.
+// See METRICS.md for the catalog.
+type userMetrics struct {
+	created  metric.Int64Counter
+	deleted  metric.Int64Counter
+	lookups  metric.Int64Counter
+	pageSize metric.Int64Histogram
+
+	// Cumulative cache outcomes backing the users.cache.hit_ratio gauge
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+}
+
+var (
+	userMetricsOnce sync.Once
+	userMetricsInst *userMetrics
+)
+
+// getUserMetrics lazily registers the instruments on first use so that
+// handlers built after metrics.InitMetrics share one set.
+func getUserMetrics() *userMetrics {
+	userMetricsOnce.Do(func() {
+		meter := metrics.GetMeter()
+		m := &userMetrics{}
+
+		m.created, _ = meter.Int64Counter("users.created",
+			metric.WithDescription("Number of users created"),
+			metric.WithUnit("{user}"),
+		)
+		m.deleted, _ = meter.Int64Counter("users.deleted",
+			metric.WithDescription("Number of users deleted"),
+			metric.WithUnit("{user}"),
+		)
+		m.lookups, _ = meter.Int64Counter("users.lookups",
+			metric.WithDescription("User lookups by ID, by outcome (hit, miss, not_found, error)"),
+			metric.WithUnit("{lookup}"),
+		)
+		m.pageSize, _ = meter.Int64Histogram("users.list.page_size",
+			metric.WithDescription("Number of users returned per list page"),
+			metric.WithUnit("{user}"),
+			metric.WithExplicitBucketBoundaries(0, 1, 5, 10, 25, 50, 100),
+		)
+
+		hitRatio, _ := meter.Float64ObservableGauge("users.cache.hit_ratio",
+			metric.WithDescription("Share of user lookups served from the cache since start"),
+			metric.WithUnit("1"),
+		)
+		_, _ = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+			hits, misses := m.cacheHits.Load(), m.cacheMisses.Load()
+			if total := hits + misses; total > 0 {
+				o.ObserveFloat64(hitRatio, float64(hits)/float64(total))
+			}
+			return nil
+		}, hitRatio)
+
+		userMetricsInst = m
+	})
+	return userMetricsInst
+}
+
+// recordLookup counts a lookup outcome and feeds the cache hit ratio.
+// not_found and error lookups were cache misses too.
+func (m *userMetrics) recordLookup(ctx context.Context, outcome string) {
+	m.lookups.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+
+	if outcome == lookupHit {
+		m.cacheHits.Add(1)
+	} else {
+		m.cacheMisses.Add(1)
+	}
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"gofiberobservability/pkg/slo"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// SLOStatus returns the declared objectives with their burn rates, remaining
+// error budget and alert state as seen by this instance.
+func SLOStatus() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		objectives := slo.GetTracker().Snapshot()
+		if objectives == nil {
+			objectives = []slo.Status{}
+		}
+		return c.JSON(fiber.Map{
+			"objectives": objectives,
+		})
+	}
+}
@@ -1,12 +1,12 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"errors"
 	"strconv"
-	"time"
 
-	"gofiberobservability/pkg/database"
+	"gofiberobservability/internal/repository"
+	"gofiberobservability/pkg/cache"
 	"gofiberobservability/pkg/logger"
 
 	"github.com/gofiber/fiber/v3"
@@ -15,13 +15,7 @@ import (
 	"go.uber.org/zap"
 )
 
-// User represents a user row.
-type User struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-}
+var tracer = otel.Tracer("gofiberobservability/handler")
 
 // CreateUserRequest is the request body for creating a user.
 type CreateUserRequest struct {
@@ -29,8 +23,16 @@ type CreateUserRequest struct {
 	Email string `json:"email"`
 }
 
+// UpdateUserRequest is the request body for updating a user.
+type UpdateUserRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
 // ListUsers returns all users from the database with pagination support.
-func ListUsers(serviceName string) fiber.Handler {
+func ListUsers(users repository.UserRepository) fiber.Handler {
+	m := getUserMetrics()
+
 	return func(c fiber.Ctx) error {
 		ctx := c.Context()
 		log := logger.GetLoggerWithTraceContext(ctx)
@@ -40,14 +42,16 @@ func ListUsers(serviceName string) fiber.Handler {
 		if limit > 100 {
 			limit = 100
 		}
+		if limit < 1 {
+			limit = 10
+		}
 		page, _ := strconv.Atoi(c.Query("page", "1"))
 		if page < 1 {
 			page = 1
 		}
 		offset := (page - 1) * limit
 
-		tr := otel.Tracer(serviceName)
-		ctx, span := tr.Start(ctx, "db.list-users")
+		ctx, span := tracer.Start(ctx, "db.list-users")
 		defer span.End()
 
 		span.SetAttributes(
@@ -55,36 +59,24 @@ func ListUsers(serviceName string) fiber.Handler {
 			attribute.Int("pagination.page", page),
 		)
 
-		rows, err := database.GetPool().Query(ctx,
-			"SELECT id, name, email, created_at FROM users ORDER BY id LIMIT $1 OFFSET $2",
-			limit, offset,
-		)
+		list, err := users.List(ctx, limit, offset)
 		if err != nil {
 			log.Error("Failed to query users", zap.Error(err))
 			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch users")
 		}
-		defer rows.Close()
 
-		users := make([]User, 0)
-		for rows.Next() {
-			var u User
-			if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.CreatedAt); err != nil {
-				log.Error("Failed to scan user row", zap.Error(err))
-				continue
-			}
-			users = append(users, u)
-		}
+		m.pageSize.Record(ctx, int64(len(list)))
 
 		log.Info("Users fetched with pagination",
-			zap.Int("count", len(users)),
+			zap.Int("count", len(list)),
 			zap.Int("limit", limit),
 			zap.Int("page", page),
 		)
 
 		return c.JSON(fiber.Map{
-			"users": users,
+			"users": list,
 			"metadata": fiber.Map{
-				"count": len(users),
+				"count": len(list),
 				"limit": limit,
 				"page":  page,
 			},
@@ -93,7 +85,9 @@ func ListUsers(serviceName string) fiber.Handler {
 }
 
 // CreateUser inserts a new user into the database.
-func CreateUser(serviceName string) fiber.Handler {
+func CreateUser(users repository.UserRepository) fiber.Handler {
+	m := getUserMetrics()
+
 	return func(c fiber.Ctx) error {
 		ctx := c.Context()
 		log := logger.GetLoggerWithTraceContext(ctx)
@@ -108,20 +102,19 @@ func CreateUser(serviceName string) fiber.Handler {
 			return fiber.NewError(fiber.StatusBadRequest, "name and email are required")
 		}
 
-		tr := otel.Tracer(serviceName)
-		ctx, span := tr.Start(ctx, "db.create-user")
+		ctx, span := tracer.Start(ctx, "db.create-user")
 		defer span.End()
 
-		var user User
-		err := database.GetPool().QueryRow(ctx,
-			"INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id, name, email, created_at",
-			req.Name, req.Email,
-		).Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt)
+		user, err := users.Create(ctx, req.Name, req.Email)
+		if errors.Is(err, repository.ErrConflict) {
+			return fiber.NewError(fiber.StatusConflict, "A user with this email already exists")
+		}
 		if err != nil {
 			log.Error("Failed to create user", zap.Error(err))
 			return fiber.NewError(fiber.StatusInternalServerError, "Failed to create user")
 		}
 
+		m.created.Add(ctx, 1)
 		span.SetAttributes(attribute.Int("user.id", user.ID))
 		log.Info("User created", zap.Int("id", user.ID), zap.String("email", user.Email))
 
@@ -132,77 +125,145 @@ func CreateUser(serviceName string) fiber.Handler {
 	}
 }
 
-// GetUser returns a single user by ID with Redis caching.
-func GetUser(serviceName string) fiber.Handler {
+// GetUser returns a single user by ID, read through the user cache.
+func GetUser(users repository.UserRepository, userCache *cache.Cache) fiber.Handler {
+	m := getUserMetrics()
+
 	return func(c fiber.Ctx) error {
 		ctx := c.Context()
 		log := logger.GetLoggerWithTraceContext(ctx)
 
 		id := c.Params("id")
-		cacheKey := fmt.Sprintf("user:%s", id)
 
-		tr := otel.Tracer(serviceName)
-		ctx, span := tr.Start(ctx, "handler.get-user")
+		ctx, span := tracer.Start(ctx, "handler.get-user")
 		defer span.End()
 
 		span.SetAttributes(attribute.String("user.id", id))
 
-		// 1. Try to get from Redis
-		val, err := database.GetRedis().Get(ctx, cacheKey).Result()
-		if err == nil {
-			// Cache Hit
-			var user User
-			if err := json.Unmarshal([]byte(val), &user); err == nil {
-				log.Info("Cache hit", zap.String("id", id))
-				span.SetAttributes(attribute.Bool("cache.hit", true))
-				return c.JSON(user)
-			}
-			log.Warn("Failed to unmarshal cached user", zap.Error(err))
-		}
-
-		// 2. Cache Miss - Get from Database
-		log.Info("Cache miss", zap.String("id", id))
-		span.SetAttributes(attribute.Bool("cache.hit", false))
-
-		var user User
-		err = database.GetPool().QueryRow(ctx,
-			"SELECT id, name, email, created_at FROM users WHERE id = $1", id,
-		).Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt)
+		// A malformed ID is the client's error, not a lookup: it stays out of the cache and lookup metrics
+		userID, err := parseUserID(id)
 		if err != nil {
-			log.Error("User not found", zap.String("id", id), zap.Error(err))
-			return fiber.NewError(fiber.StatusNotFound, "User not found")
+			return err
 		}
 
-		// 3. Save to Redis
-		userJSON, _ := json.Marshal(user)
-		database.GetRedis().Set(ctx, cacheKey, userJSON, 10*time.Minute)
+		user, outcome, err := cache.GetOrLoad(ctx, userCache, userCacheKey(userID), func(ctx context.Context) (repository.User, error) {
+			return users.Get(ctx, userID)
+		})
+		hit := outcome == cache.OutcomeHit || outcome == cache.OutcomeStale
+		span.SetAttributes(attribute.Bool("cache.hit", hit))
+
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			log.Info("User not found", zap.String("id", id))
+			m.recordLookup(ctx, lookupNotFound)
+			return fiber.NewError(fiber.StatusNotFound, "User not found")
+		case err != nil:
+			log.Error("Failed to fetch user", zap.String("id", id), zap.Error(err))
+			m.recordLookup(ctx, lookupError)
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch user")
+		case hit:
+			log.Info("Cache hit", zap.String("id", id), zap.String("cache.outcome", string(outcome)))
+			m.recordLookup(ctx, lookupHit)
+		default:
+			log.Info("Cache miss", zap.String("id", id), zap.String("cache.outcome", string(outcome)))
+			m.recordLookup(ctx, lookupMiss)
+		}
 
 		return c.JSON(user)
 	}
 }
 
-// DeleteUser deletes a user by ID.
-func DeleteUser(serviceName string) fiber.Handler {
+// parseUserID parses a user ID path parameter, answering 400 if it isn't a number.
+func parseUserID(id string) (int, error) {
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+	return userID, nil
+}
+
+// userCacheKey is the cache key of the user with the given ID.
+func userCacheKey(id int) string {
+	return "user:" + strconv.Itoa(id)
+}
+
+// UpdateUser replaces the name and email of a user and invalidates its cache
+// entry.
+func UpdateUser(users repository.UserRepository, userCache *cache.Cache) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		ctx := c.Context()
+		log := logger.GetLoggerWithTraceContext(ctx)
+
+		id, err := parseUserID(c.Params("id"))
+		if err != nil {
+			return err
+		}
+
+		var req UpdateUserRequest
+		if err := c.Bind().JSON(&req); err != nil {
+			log.Error("Invalid request body", zap.Error(err))
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+		if req.Name == "" || req.Email == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "name and email are required")
+		}
+
+		ctx, span := tracer.Start(ctx, "db.update-user")
+		defer span.End()
+		span.SetAttributes(attribute.Int("user.id", id))
+
+		user, err := users.Update(ctx, repository.User{ID: id, Name: req.Name, Email: req.Email})
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "User not found")
+		case errors.Is(err, repository.ErrConflict):
+			return fiber.NewError(fiber.StatusConflict, "A user with this email already exists")
+		case err != nil:
+			log.Error("Failed to update user", zap.Int("id", id), zap.Error(err))
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to update user")
+		}
+
+		// Delete rather than overwrite, so concurrent updates can't leave the older value cached
+		userCache.Invalidate(ctx, userCacheKey(id))
+
+		log.Info("User updated", zap.Int("id", id))
+
+		return c.JSON(fiber.Map{
+			"message": "User updated",
+			"user":    user,
+		})
+	}
+}
+
+// DeleteUser deletes a user by ID and invalidates its cache entry.
+func DeleteUser(users repository.UserRepository, userCache *cache.Cache) fiber.Handler {
+	m := getUserMetrics()
+
 	return func(c fiber.Ctx) error {
 		ctx := c.Context()
 		log := logger.GetLoggerWithTraceContext(ctx)
 
 		id := c.Params("id")
+		userID, err := parseUserID(id)
+		if err != nil {
+			return err
+		}
 
-		tr := otel.Tracer(serviceName)
-		ctx, span := tr.Start(ctx, "db.delete-user")
+		ctx, span := tracer.Start(ctx, "db.delete-user")
 		defer span.End()
 
-		tag, err := database.GetPool().Exec(ctx, "DELETE FROM users WHERE id = $1", id)
+		err = users.Delete(ctx, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "User not found")
+		}
 		if err != nil {
 			log.Error("Failed to delete user", zap.String("id", id), zap.Error(err))
 			return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete user")
 		}
 
-		if tag.RowsAffected() == 0 {
-			return fiber.NewError(fiber.StatusNotFound, "User not found")
-		}
+		userCache.Invalidate(ctx, userCacheKey(userID))
 
+		m.deleted.Add(ctx, 1)
 		log.Info("User deleted", zap.String("id", id))
 
 		return c.JSON(fiber.Map{
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// AdminAuth returns a middleware that only lets through requests carrying
+// "Authorization: Bearer <token>". With an empty token every request is
+// rejected, so operator endpoints stay closed until ADMIN_TOKEN is set.
+func AdminAuth(token string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if !IsAdmin(c, token) {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="admin"`)
+			return fiber.NewError(fiber.StatusUnauthorized, "Admin token required")
+		}
+		return c.Next()
+	}
+}
+
+// IsAdmin reports whether the request carries the admin bearer token.
+func IsAdmin(c fiber.Ctx, token string) bool {
+	if token == "" {
+		return false
+	}
+	got, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	// defaultUnmatchedRoute is the http.route metric value for requests no route matched
+	defaultUnmatchedRoute = "unmatched"
+	// otherValue replaces attribute values seen after the per-attribute limit is reached
+	otherValue = "other"
+
+	// AttributeFoldedMetric counts values folded into "other"
+	AttributeFoldedMetric = "http.server.metric.attribute.folded"
+)
+
+// attributeGuard caps the number of distinct values each metric attribute may
+// take. The first limit values of a key pass through; later ones are folded
+// into "other" and counted, so a noisy dimension shows up instead of
+// silently multiplying series.
+type attributeGuard struct {
+	limit  int
+	folded metric.Int64Counter
+
+	mu   sync.RWMutex
+	seen map[attribute.Key]map[string]struct{}
+}
+
+// newAttributeGuard returns nil (no limit) when limit <= 0.
+func newAttributeGuard(meter metric.Meter, limit int) *attributeGuard {
+	if limit <= 0 {
+		return nil
+	}
+	folded, _ := meter.Int64Counter(AttributeFoldedMetric,
+		metric.WithDescription("Metric attribute values folded into \"other\" after the per-attribute limit was reached"),
+		metric.WithUnit("{value}"),
+	)
+	return &attributeGuard{
+		limit:  limit,
+		folded: folded,
+		seen:   make(map[attribute.Key]map[string]struct{}),
+	}
+}
+
+// value returns v if it is already known or there is room for it, otherwise
+// "other", counting the fold.
+func (g *attributeGuard) value(ctx context.Context, key attribute.Key, v string) string {
+	if g == nil {
+		return v
+	}
+	if g.admit(key, v) {
+		return v
+	}
+	g.folded.Add(ctx, 1, metric.WithAttributes(attribute.String("attribute", string(key))))
+	return otherValue
+}
+
+// attr is value wrapped into a string attribute.
+func (g *attributeGuard) attr(ctx context.Context, key attribute.Key, v string) attribute.KeyValue {
+	return key.String(g.value(ctx, key, v))
+}
+
+// quietAttr is attr without counting the fold, for attributes that are
+// recorded again (and counted) when the request completes.
+func (g *attributeGuard) quietAttr(key attribute.Key, v string) attribute.KeyValue {
+	if g != nil && !g.admit(key, v) {
+		v = otherValue
+	}
+	return key.String(v)
+}
+
+// admit reports whether v is known for key, remembering it if there is room.
+func (g *attributeGuard) admit(key attribute.Key, v string) bool {
+	g.mu.RLock()
+	_, known := g.seen[key][v]
+	g.mu.RUnlock()
+	if known {
+		return true
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	values := g.seen[key]
+	if values == nil {
+		values = make(map[string]struct{})
+		g.seen[key] = values
+	}
+	if _, known = values[v]; known {
+		return true
+	}
+	if len(values) >= g.limit {
+		return false
+	}
+	values[v] = struct{}{}
+	return true
+}
+
+// metricRoute is the http.route metric value: the route template, or the
+// fixed unmatched label so 404 scans share one series.
+func metricRoute(route string, o *options) string {
+	if route == "" {
+		return o.unmatchedRoute
+	}
+	return route
+}
+
+// statusAttr is the http.response.status_code metric attribute. With status
+// classes enabled it is "2xx", "4xx", ...; the string still matches the
+// status_code=~"5.." selectors used by the dashboards.
+func statusAttr(status int, o *options) attribute.KeyValue {
+	if o.statusClasses {
+		return semconv.HTTPResponseStatusCodeKey.String(strconv.Itoa(status/100) + "xx")
+	}
+	return semconv.HTTPResponseStatusCode(status)
+}
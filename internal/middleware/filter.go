@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Signal selects which kind of telemetry a filter rule suppresses.
+type Signal uint8
+
+const (
+	SignalTracing Signal = 1 << iota
+	SignalLogging
+	SignalMetrics
+
+	// AllSignals suppresses spans, log lines and metric points.
+	AllSignals = SignalTracing | SignalLogging | SignalMetrics
+)
+
+// FilterRule excludes matching requests from the given signals.
+// A request matches when any of Paths, PathRegex or Predicate matches and,
+// if Methods is set, the request method is listed.
+type FilterRule struct {
+	// Paths are glob patterns in path.Match syntax, e.g. "/health" or "/static/*".
+	Paths []string
+	// PathRegex are regular expressions matched against the request path.
+	PathRegex []string
+	// Methods restricts the rule to these HTTP methods; a rule with only
+	// Methods set matches every path for those methods.
+	Methods []string
+	// Predicate is an escape hatch for anything the lists can't express.
+	Predicate func(c fiber.Ctx) bool
+	// Signals to suppress; zero means AllSignals.
+	Signals Signal
+}
+
+type compiledRule struct {
+	globs     []string
+	regexps   []*regexp.Regexp
+	methods   map[string]struct{}
+	predicate func(c fiber.Ctx) bool
+	signals   Signal
+}
+
+// Filter decides per request which signals the middleware should skip.
+type Filter struct {
+	rules []compiledRule
+}
+
+// NewFilter validates and compiles the given rules.
+func NewFilter(rules ...FilterRule) (*Filter, error) {
+	f := &Filter{rules: make([]compiledRule, 0, len(rules))}
+
+	for _, r := range rules {
+		cr := compiledRule{predicate: r.Predicate, signals: r.Signals}
+		if cr.signals == 0 {
+			cr.signals = AllSignals
+		}
+
+		for _, g := range r.Paths {
+			if _, err := path.Match(g, ""); err != nil {
+				return nil, fmt.Errorf("invalid path glob %q: %w", g, err)
+			}
+			cr.globs = append(cr.globs, g)
+		}
+		for _, expr := range r.PathRegex {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path regex %q: %w", expr, err)
+			}
+			cr.regexps = append(cr.regexps, re)
+		}
+		if len(r.Methods) > 0 {
+			cr.methods = make(map[string]struct{}, len(r.Methods))
+			for _, m := range r.Methods {
+				cr.methods[strings.ToUpper(m)] = struct{}{}
+			}
+		}
+
+		f.rules = append(f.rules, cr)
+	}
+
+	return f, nil
+}
+
+// Skipped returns the set of signals suppressed for this request.
+func (f *Filter) Skipped(c fiber.Ctx) Signal {
+	if f == nil {
+		return 0
+	}
+
+	var skipped Signal
+	for i := range f.rules {
+		r := &f.rules[i]
+		if skipped&r.signals == r.signals {
+			continue // nothing left for this rule to add
+		}
+		if r.matches(c) {
+			skipped |= r.signals
+		}
+	}
+	return skipped
+}
+
+func (r *compiledRule) matches(c fiber.Ctx) bool {
+	if r.methods != nil {
+		if _, ok := r.methods[c.Method()]; !ok {
+			return false
+		}
+		if len(r.globs) == 0 && len(r.regexps) == 0 && r.predicate == nil {
+			return true
+		}
+	}
+
+	p := c.Path()
+	for _, g := range r.globs {
+		if ok, _ := path.Match(g, p); ok {
+			return true
+		}
+	}
+	for _, re := range r.regexps {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return r.predicate != nil && r.predicate(c)
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"strings"
 	"time"
 
 	"gofiberobservability/pkg/logger"
@@ -8,111 +9,258 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.uber.org/zap"
 )
 
+// Instrument names recorded by LoggingMiddleware. cmd/rulesgen derives the
+// Prometheus recording rules from them, so a rename only happens here.
+const (
+	RequestDurationMetric = "http.server.request.duration"
+	ActiveRequestsMetric  = "http.server.active_requests"
+)
+
 // LoggingMiddleware logs incoming requests and outgoing responses with OpenTelemetry trace correlation and metrics
-func LoggingMiddleware() fiber.Handler {
-	// Initialize metrics for the middleware
+func LoggingMiddleware(opts ...Option) fiber.Handler {
+	o := newOptions(opts)
+
+	// Initialize metrics for the middleware (stable HTTP server semantic conventions)
 	meter := metrics.GetMeter()
-	requestCount, _ := meter.Int64Counter("http.requests_total",
-		metric.WithDescription("Total number of HTTP requests"),
-		metric.WithUnit("{request}"),
+	requestDuration, _ := meter.Float64Histogram(RequestDurationMetric,
+		metric.WithDescription("Duration of HTTP server requests"),
+		metric.WithUnit("s"),
+		// Sub-millisecond boundaries resolve cache hits and other fast handlers
+		metric.WithExplicitBucketBoundaries(0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10),
 	)
-	requestDuration, _ := meter.Float64Histogram("http.request.duration_ms",
-		metric.WithDescription("HTTP request duration in milliseconds"),
-		metric.WithUnit("ms"),
+	requestSize, _ := meter.Int64Histogram("http.server.request.body.size",
+		metric.WithDescription("Size of HTTP server request bodies"),
+		metric.WithUnit("By"),
 	)
-	requestSize, _ := meter.Int64Histogram("http.request.size_bytes",
-		metric.WithDescription("HTTP request body size in bytes"),
+	responseSize, _ := meter.Int64Histogram("http.server.response.body.size",
+		metric.WithDescription("Size of HTTP server response bodies"),
 		metric.WithUnit("By"),
 	)
-	responseSize, _ := meter.Int64Histogram("http.response.size_bytes",
-		metric.WithDescription("HTTP response body size in bytes"),
+
+	// Saturation: requests and request body bytes currently being handled
+	activeRequests, _ := meter.Int64UpDownCounter(ActiveRequestsMetric,
+		metric.WithDescription("Number of active HTTP server requests"),
+		metric.WithUnit("{request}"),
+	)
+	inflightBodyBytes, _ := meter.Int64UpDownCounter("http.server.request.body.inflight",
+		metric.WithDescription("Request body bytes held by requests that are still being handled"),
 		metric.WithUnit("By"),
 	)
+	routes := &routeMatcher{}
+	guard := newAttributeGuard(meter, o.attributeValueLimit)
+
+	// Legacy instruments, only recorded in "http/dup" mode
+	var legacy *legacyInstruments
+	if o.emitLegacy {
+		legacy = newLegacyInstruments(meter)
+	}
 
 	return func(c fiber.Ctx) error {
+		skipped := o.filter.Skipped(c)
+		skipLogs := skipped&SignalLogging != 0
+		skipMetrics := skipped&SignalMetrics != 0
+		if skipLogs && skipMetrics {
+			return c.Next()
+		}
+
 		start := time.Now()
 
+		// TracingMiddleware extracts baggage along with the trace context; when
+		// it is disabled or skipped the request, read the W3C header here
+		if baggage.FromContext(c.Context()).Len() == 0 && c.Get("baggage") != "" {
+			c.SetContext(propagation.Baggage{}.Extract(c.Context(), propagation.HeaderCarrier(c.GetReqHeaders())))
+		}
+
+		if !skipMetrics {
+			// The router hasn't run yet, so resolve the route template up front;
+			// the same attribute set must be used for the increment and decrement.
+			ctx := c.Context()
+			activeSet := metric.WithAttributeSet(attribute.NewSet(
+				guard.quietAttr(semconv.HTTPRequestMethodKey, strings.Clone(c.Method())),
+				semconv.URLScheme(strings.Clone(c.Scheme())),
+				guard.quietAttr(semconv.HTTPRouteKey, metricRoute(routes.Match(c), o)),
+			))
+			bodySize := int64(len(c.Request().Body()))
+
+			activeRequests.Add(ctx, 1, activeSet)
+			inflightBodyBytes.Add(ctx, bodySize, activeSet)
+			defer func() {
+				activeRequests.Add(ctx, -1, activeSet)
+				inflightBodyBytes.Add(ctx, -bodySize, activeSet)
+			}()
+		}
+
 		// Get logger with trace context
 		log := logger.GetLoggerWithTraceContext(c.Context())
 
+		// Clone request strings: the OTLP log exporter keeps them after the pooled ctx is reused
+		method := strings.Clone(c.Method())
+		path := strings.Clone(c.Path())
+		userAgent := strings.Clone(c.Get(fiber.HeaderUserAgent))
+		clientIP := strings.Clone(c.IP())
+
 		// Log incoming request
-		log.Info("Incoming request",
-			zap.String("http.method", c.Method()),
-			zap.String("http.route", c.Route().Path),
-			zap.String("http.path", c.Path()),
-			zap.String("http.user_agent", c.Get("User-Agent")),
-			zap.String("http.client_ip", c.IP()),
-		)
+		fields := []zap.Field{
+			zap.String(string(semconv.HTTPRequestMethodKey), method),
+			zap.String(string(semconv.URLPathKey), path),
+			zap.String(string(semconv.UserAgentOriginalKey), userAgent),
+			zap.String(string(semconv.ClientAddressKey), clientIP),
+		}
+		if o.emitLegacy {
+			fields = append(fields,
+				zap.String("http.method", method),
+				zap.String("http.path", path),
+				zap.String("http.user_agent", userAgent),
+				zap.String("http.client_ip", clientIP),
+			)
+		}
+		if !skipLogs {
+			log.Info("Incoming request", fields...)
+		}
 
-		// Process request
-		err := c.Next()
+		// complete records the metrics, SLO event and completion log of a request
+		// ending with status (the one the ErrorHandler will send) and err.
+		complete := func(status int, err error) {
+			duration := time.Since(start)
+			route := routePattern(c)
+			errType := errorType(err, status)
 
-		// Calculate duration
-		duration := time.Since(start)
-		statusCode := c.Response().StatusCode()
+			if !skipMetrics {
+				// Performance Optimization: build the attribute set once and share it between instruments.
+				// Free-form values go through the guard so no attribute can grow without bound.
+				ctx := c.Context()
+				attrs := []attribute.KeyValue{
+					guard.attr(ctx, semconv.HTTPRequestMethodKey, method),
+					semconv.URLScheme(strings.Clone(c.Scheme())),
+					semconv.NetworkProtocolVersion(protocolVersion(c)),
+					statusAttr(status, o),
+					guard.attr(ctx, semconv.HTTPRouteKey, metricRoute(route, o)),
+				}
+				if errType != "" {
+					attrs = append(attrs, guard.attr(ctx, semconv.ErrorTypeKey, errType))
+				}
+				if len(o.baggageMetricKeys) > 0 {
+					bag := baggage.FromContext(ctx)
+					for _, key := range o.baggageMetricKeys {
+						if m := bag.Member(key); m.Key() != "" {
+							attrs = append(attrs, guard.attr(ctx, attribute.Key(key), m.Value()))
+						}
+					}
+				}
+				attrSet := metric.WithAttributeSet(attribute.NewSet(attrs...))
 
-		// If there's an error and the status code is still 200, it means the error handler
-		// hasn't run yet. We should try to determine the intended status code.
-		if err != nil && statusCode == fiber.StatusOK {
-			statusCode = fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				statusCode = e.Code
+				// Record latency (traffic and errors are derived from the histogram count)
+				requestDuration.Record(ctx, duration.Seconds(), attrSet)
+
+				// Record sizes
+				reqSize := int64(len(c.Request().Body()))
+				respSize := int64(len(c.Response().Body()))
+				requestSize.Record(ctx, reqSize, attrSet)
+				responseSize.Record(ctx, respSize, attrSet)
+
+				if legacy != nil {
+					legacy.record(c, method, metricRoute(route, o), status, duration, reqSize, respSize)
+				}
+
+				o.slo.Observe(ctx, method, route, status, duration)
 			}
-		}
 
-		// Performance Optimization: Pass attributes directly to avoid slice allocations where possible
-		// Note: OTEL SDKs are optimized for this pattern
-		method := c.Method()
-		route := c.Route().Path
-
-		// Record traffic and errors
-		requestCount.Add(c.Context(), 1, metric.WithAttributes(
-			attribute.String("http.method", method),
-			attribute.String("http.route", route),
-			attribute.Int("http.status_code", statusCode),
-		))
-
-		// Record latency
-		requestDuration.Record(c.Context(), float64(duration.Milliseconds()), metric.WithAttributes(
-			attribute.String("http.method", method),
-			attribute.String("http.route", route),
-			attribute.Int("http.status_code", statusCode),
-		))
-
-		// Record sizes
-		reqSize := int64(len(c.Request().Body()))
-		respSize := int64(len(c.Response().Body()))
-
-		requestSize.Record(c.Context(), reqSize, metric.WithAttributes(
-			attribute.String("http.method", method),
-			attribute.String("http.route", route),
-		))
-		responseSize.Record(c.Context(), respSize, metric.WithAttributes(
-			attribute.String("http.method", method),
-			attribute.String("http.route", route),
-		))
-
-		// Log response (Optimized zap fields)
-		log.Info("Request completed",
-			zap.String("http.method", method),
-			zap.String("http.route", route),
-			zap.Int("http.status_code", statusCode),
-			zap.Int64("http.request.duration_ms", duration.Milliseconds()),
-		)
-
-		// Log error if present
-		if err != nil {
-			log.Error("Request error",
-				zap.String("http.method", method),
-				zap.String("http.path", c.Path()),
-				zap.Error(err),
-			)
+			if skipLogs {
+				return
+			}
+
+			// Log response (Optimized zap fields)
+			fields = []zap.Field{
+				zap.String(string(semconv.HTTPRequestMethodKey), method),
+				zap.String(string(semconv.HTTPRouteKey), route),
+				zap.Int(string(semconv.HTTPResponseStatusCodeKey), status),
+				zap.Float64("http.server.request.duration", duration.Seconds()),
+			}
+			if o.emitLegacy {
+				fields = append(fields,
+					zap.String("http.method", method),
+					zap.Int("http.status_code", status),
+					zap.Float64("http.request.duration_ms", float64(duration)/float64(time.Millisecond)),
+				)
+			}
+			log.Info("Request completed", fields...)
+
+			// Log error if present
+			if err != nil {
+				log.Error("Request error",
+					zap.String(string(semconv.HTTPRequestMethodKey), method),
+					zap.String(string(semconv.URLPathKey), path),
+					zap.String(string(semconv.ErrorTypeKey), errType),
+					zap.Error(err),
+				)
+			}
 		}
 
+		// A panic is recorded as the 500 RecoveryMiddleware sends, so error rates
+		// and SLOs see it, then re-raised for RecoveryMiddleware to handle
+		defer func() {
+			if r := recover(); r != nil {
+				complete(fiber.StatusInternalServerError, nil)
+				panic(r)
+			}
+		}()
+
+		// Process request
+		err := c.Next()
+		complete(statusCode(c, err), err)
 		return err
 	}
 }
+
+// legacyInstruments are the pre-stable metric names the existing dashboards were built on.
+type legacyInstruments struct {
+	requestCount    metric.Int64Counter
+	requestDuration metric.Float64Histogram
+	requestSize     metric.Int64Histogram
+	responseSize    metric.Int64Histogram
+}
+
+func newLegacyInstruments(meter metric.Meter) *legacyInstruments {
+	l := &legacyInstruments{}
+	l.requestCount, _ = meter.Int64Counter("http.requests_total",
+		metric.WithDescription("Total number of HTTP requests"),
+		metric.WithUnit("{request}"),
+	)
+	l.requestDuration, _ = meter.Float64Histogram("http.request.duration_ms",
+		metric.WithDescription("HTTP request duration in milliseconds"),
+		metric.WithUnit("ms"),
+	)
+	l.requestSize, _ = meter.Int64Histogram("http.request.size_bytes",
+		metric.WithDescription("HTTP request body size in bytes"),
+		metric.WithUnit("By"),
+	)
+	l.responseSize, _ = meter.Int64Histogram("http.response.size_bytes",
+		metric.WithDescription("HTTP response body size in bytes"),
+		metric.WithUnit("By"),
+	)
+	return l
+}
+
+func (l *legacyInstruments) record(c fiber.Ctx, method, route string, status int, duration time.Duration, reqSize, respSize int64) {
+	withStatus := metric.WithAttributes(
+		legacyMethodKey.String(method),
+		attribute.String("http.route", route),
+		legacyStatusCodeKey.Int(status),
+	)
+	withoutStatus := metric.WithAttributes(
+		legacyMethodKey.String(method),
+		attribute.String("http.route", route),
+	)
+
+	l.requestCount.Add(c.Context(), 1, withStatus)
+	l.requestDuration.Record(c.Context(), float64(duration)/float64(time.Millisecond), withStatus)
+	l.requestSize.Record(c.Context(), reqSize, withoutStatus)
+	l.responseSize.Record(c.Context(), respSize, withoutStatus)
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"strings"
+
+	"gofiberobservability/pkg/slo"
+)
+
+// Option customizes TracingMiddleware and LoggingMiddleware.
+type Option func(*options)
+
+type options struct {
+	// emitLegacy keeps the pre-stable HTTP attribute and metric names alive next to the stable ones
+	emitLegacy bool
+	// filter suppresses telemetry for matching requests (nil = record everything)
+	filter *Filter
+	// baggageMetricKeys are baggage members added as metric dimensions
+	baggageMetricKeys []string
+
+	// Metric cardinality guard
+	unmatchedRoute      string // http.route value for unmatched requests
+	statusClasses       bool   // bucket http.response.status_code to 2xx/4xx/5xx
+	attributeValueLimit int    // distinct values per attribute before folding into "other" (0 = off)
+
+	// slo classifies finished requests against the declared objectives (nil = off)
+	slo *slo.Tracker
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{unmatchedRoute: defaultUnmatchedRoute}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithSemconvStabilityOptIn follows OTEL_SEMCONV_STABILITY_OPT_IN semantics:
+// "http" emits only the stable HTTP conventions, "http/dup" emits both the stable
+// and the legacy names so existing dashboards keep working during migration.
+func WithSemconvStabilityOptIn(value string) Option {
+	return func(o *options) {
+		for _, v := range strings.Split(value, ",") {
+			if strings.TrimSpace(v) == "http/dup" {
+				o.emitLegacy = true
+			}
+		}
+	}
+}
+
+// WithFilter skips tracing, logging and/or metrics for requests matched by f.
+func WithFilter(f *Filter) Option {
+	return func(o *options) {
+		o.filter = f
+	}
+}
+
+// WithBaggageMetricKeys adds the allowlisted baggage members as metric attributes.
+// Every distinct value creates a new series, so only list low-cardinality keys.
+func WithBaggageMetricKeys(keys ...string) Option {
+	return func(o *options) {
+		o.baggageMetricKeys = keys
+	}
+}
+
+// WithUnmatchedRouteLabel sets the http.route metric value used for requests
+// no route matched (404 scans, bots), so they collapse into a single series.
+func WithUnmatchedRouteLabel(label string) Option {
+	return func(o *options) {
+		if label != "" {
+			o.unmatchedRoute = label
+		}
+	}
+}
+
+// WithStatusClasses records http.response.status_code on metrics as its class
+// ("2xx", "4xx", "5xx") instead of the exact code.
+func WithStatusClasses(enabled bool) Option {
+	return func(o *options) {
+		o.statusClasses = enabled
+	}
+}
+
+// WithAttributeValueLimit caps the distinct values of each metric attribute;
+// excess values are recorded as "other" and counted on
+// http.server.metric.attribute.folded. Zero disables the limit.
+func WithAttributeValueLimit(limit int) Option {
+	return func(o *options) {
+		o.attributeValueLimit = limit
+	}
+}
+
+// WithSLO feeds every finished request to t so it can track its objectives.
+func WithSLO(t *slo.Tracker) Option {
+	return func(o *options) {
+		o.slo = t
+	}
+}
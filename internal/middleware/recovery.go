@@ -16,14 +16,12 @@ func RecoveryMiddleware(log *zap.Logger) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		defer func() {
 			if r := recover(); r != nil {
-				err, ok := r.(error)
-				if !ok {
-					err = fmt.Errorf("%v", r)
-				}
+				err := panicError(r)
 
 				stack := debug.Stack()
 
-				// Get trace span
+				// The server span is only still recording when this runs inside
+				// TracingMiddleware; registered outside it, TracingMiddleware records the panic
 				span := trace.SpanFromContext(c.Context())
 				if span.IsRecording() {
 					span.SetStatus(codes.Error, "panic recovered")
@@ -50,3 +48,11 @@ func RecoveryMiddleware(log *zap.Logger) fiber.Handler {
 		return c.Next()
 	}
 }
+
+// panicError turns a recovered value into an error.
+func panicError(r any) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", r)
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// routeMatcher guesses a request's route template before the router runs.
+// Fiber only exposes the matched route once c.Next has returned, which is
+// where every other http.route value comes from (routePattern); only
+// instruments incremented on entry (active requests) need it up front.
+// Patterns made of static and plain ":param" segments are split once when the
+// table is built; anything else Fiber's syntax allows (optional and
+// constrained parameters, wildcards) is left to fiber.RoutePatternMatch, with
+// the app's case sensitivity and strict routing. A request no route matches
+// reports "" and gets the unmatched label.
+type routeMatcher struct {
+	once   sync.Once
+	cfg    fiber.Config
+	routes map[string][]compiledRoute // keyed by method, in registration order
+}
+
+// compiledRoute is a route path and, when it only uses static and ":param"
+// segments, those segments (":" for a parameter).
+type compiledRoute struct {
+	path     string
+	segments []string
+	simple   bool
+}
+
+// Match returns the route template for the request, or "".
+func (m *routeMatcher) Match(c fiber.Ctx) string {
+	m.once.Do(func() { m.build(c.App()) })
+
+	path := c.Path()
+	if !m.cfg.StrictRouting && len(path) > 1 {
+		// The router ignores trailing slashes; RoutePatternMatch only trims the pattern
+		path = strings.TrimRight(path, "/")
+	}
+	for _, route := range m.routes[c.Method()] {
+		if route.simple {
+			if m.matchSegments(path, route.segments) {
+				return route.path
+			}
+		} else if fiber.RoutePatternMatch(path, route.path, m.cfg) {
+			return route.path
+		}
+	}
+	return ""
+}
+
+// build snapshots the registered routes. Routes are registered before the
+// server starts, so the first request sees the complete table.
+func (m *routeMatcher) build(app *fiber.App) {
+	m.cfg = app.Config()
+	m.routes = make(map[string][]compiledRoute)
+	for _, r := range app.GetRoutes(true) {
+		m.routes[r.Method] = append(m.routes[r.Method], compileRoute(r.Path, m.cfg.StrictRouting))
+	}
+}
+
+// compileRoute splits path into segments if every segment is either static
+// or a whole ":name" parameter.
+func compileRoute(path string, strict bool) compiledRoute {
+	route := compiledRoute{path: path}
+	pattern := path
+	if !strict && len(pattern) > 1 {
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		switch {
+		case strings.HasPrefix(seg, ":") && len(seg) > 1 && isParamName(seg[1:]):
+			segments[i] = ":"
+		case strings.ContainsAny(seg, ":*+?<>()\\"):
+			return route
+		}
+	}
+	route.segments, route.simple = segments, true
+	return route
+}
+
+func isParamName(name string) bool {
+	for _, r := range name {
+		if r != '_' && (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// matchSegments reports whether path matches a compiled route: static
+// segments compare equal (ignoring case unless the app is case sensitive),
+// parameters take one non-empty segment.
+func (m *routeMatcher) matchSegments(path string, segments []string) bool {
+	for i, seg := range segments {
+		next, rest, found := strings.Cut(path, "/")
+		if found != (i < len(segments)-1) {
+			return false
+		}
+		switch {
+		case seg == ":":
+			if next == "" {
+				return false
+			}
+		case m.cfg.CaseSensitive:
+			if next != seg {
+				return false
+			}
+		default:
+			if !strings.EqualFold(next, seg) {
+				return false
+			}
+		}
+		path = rest
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Legacy (pre-stable) HTTP attribute keys, only emitted in "http/dup" mode.
+const (
+	legacyMethodKey     = attribute.Key("http.method")
+	legacyStatusCodeKey = attribute.Key("http.status_code")
+	legacyURLKey        = attribute.Key("http.url")
+	legacyTargetKey     = attribute.Key("http.target")
+	legacyClientIPKey   = attribute.Key("http.client_ip")
+	legacyUserAgentKey  = attribute.Key("http.user_agent")
+)
+
+// statusCode returns the status code the client will actually receive.
+// Middleware runs before the app ErrorHandler, so a returned error has not been
+// written to the response yet; mirror the ErrorHandler's mapping instead.
+func statusCode(c fiber.Ctx, err error) int {
+	if err != nil {
+		var e *fiber.Error
+		if errors.As(err, &e) {
+			return e.Code
+		}
+		return fiber.StatusInternalServerError
+	}
+	return c.Response().StatusCode()
+}
+
+// routePattern returns the matched route template, or "" when no route matched
+// (the router only knows the final route after c.Next has returned).
+func routePattern(c fiber.Ctx) string {
+	if !c.Matched() {
+		return ""
+	}
+	return c.Route().Path
+}
+
+// errorType returns the low-cardinality error.type value for a server request,
+// or "" when the request did not fail.
+func errorType(err error, status int) string {
+	var e *fiber.Error
+	if err != nil && !errors.As(err, &e) {
+		return fmt.Sprintf("%T", err)
+	}
+	if status >= fiber.StatusInternalServerError {
+		return strconv.Itoa(status)
+	}
+	return ""
+}
+
+// protocolVersion turns "HTTP/1.1" into "1.1".
+func protocolVersion(c fiber.Ctx) string {
+	return strings.Clone(strings.TrimPrefix(c.Protocol(), "HTTP/"))
+}
+
+// spanName follows the "{method} {route}" convention, falling back to the bare method
+// for unmatched requests to keep span names low-cardinality.
+func spanName(method, route string) string {
+	if route == "" {
+		return method
+	}
+	return method + " " + route
+}
+
+// requestAttributes are known before the handler chain runs.
+// Fiber's getters return zero-copy strings backed by the pooled request buffer,
+// so everything that outlives the request (spans are exported later) is cloned.
+func requestAttributes(c fiber.Ctx, o *options) []attribute.KeyValue {
+	method := strings.Clone(c.Method())
+	path := strings.Clone(c.Path())
+	clientIP := strings.Clone(c.IP())
+	userAgent := strings.Clone(c.Get(fiber.HeaderUserAgent))
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.URLPath(path),
+		semconv.URLScheme(strings.Clone(c.Scheme())),
+		semconv.ServerAddress(strings.Clone(c.Hostname())),
+		semconv.NetworkProtocolVersion(protocolVersion(c)),
+		semconv.ClientAddress(clientIP),
+		semconv.UserAgentOriginal(userAgent),
+	}
+	if o.emitLegacy {
+		attrs = append(attrs,
+			legacyMethodKey.String(method),
+			legacyURLKey.String(strings.Clone(c.OriginalURL())),
+			legacyTargetKey.String(path),
+			legacyClientIPKey.String(clientIP),
+			legacyUserAgentKey.String(userAgent),
+		)
+	}
+	return attrs
+}
+
+// responseAttributes are only known once the handler chain has returned.
+func responseAttributes(route string, status int, errType string, o *options) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.HTTPResponseStatusCode(status)}
+	if route != "" {
+		attrs = append(attrs, semconv.HTTPRoute(route))
+	}
+	if errType != "" {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(errType))
+	}
+	if o.emitLegacy {
+		attrs = append(attrs, legacyStatusCodeKey.Int(status))
+	}
+	return attrs
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+
+	"gofiberobservability/pkg/metrics"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RegisterServerMetrics exports connection-level saturation gauges of the
+// underlying fasthttp server. fasthttp has no request queue: connections are
+// served by a worker each and rejected once Concurrency is reached, so
+// concurrency against its limit is the queueing signal.
+func RegisterServerMetrics(app *fiber.App) error {
+	meter := metrics.GetMeter()
+
+	openConns, err := meter.Int64ObservableGauge("http.server.open_connections",
+		metric.WithDescription("Number of open client connections"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+	concurrency, err := meter.Int64ObservableGauge("http.server.concurrency",
+		metric.WithDescription("Number of connections currently held by a server worker"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+	concurrencyLimit, err := meter.Int64ObservableGauge("http.server.concurrency.limit",
+		metric.WithDescription("Maximum number of concurrent connections before new ones are rejected"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		server := app.Server()
+		if server == nil {
+			return nil // not listening yet
+		}
+		o.ObserveInt64(openConns, int64(server.GetOpenConnectionsCount()))
+		o.ObserveInt64(concurrency, int64(server.GetCurrentConcurrency()))
+		limit := server.Concurrency
+		if limit <= 0 {
+			limit = fasthttp.DefaultConcurrency
+		}
+		o.ObserveInt64(concurrencyLimit, int64(limit))
+		return nil
+	}, openConns, concurrency, concurrencyLimit)
+
+	return err
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"strings"
+
 	"github.com/gofiber/fiber/v3"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -10,33 +12,44 @@ import (
 )
 
 // TracingMiddleware creates a custom OpenTelemetry tracing middleware for Fiber v3
-func TracingMiddleware(serviceName string) fiber.Handler {
+func TracingMiddleware(serviceName string, opts ...Option) fiber.Handler {
+	o := newOptions(opts)
 	tracer := otel.Tracer("gofiber-v3-tracing")
 	propagator := otel.GetTextMapPropagator()
 
 	return func(c fiber.Ctx) error {
+		if o.filter.Skipped(c)&SignalTracing != 0 {
+			return c.Next()
+		}
+
 		// Extract context from headers (propagation)
 		ctx := propagator.Extract(c.Context(), propagation.HeaderCarrier(c.GetReqHeaders()))
 
-		// Start span
-		spanName := c.Method() + " " + c.Path()
-		if route := c.Route(); route != nil {
-			spanName = c.Method() + " " + route.Path
-		}
-
-		ctx, span := tracer.Start(ctx, spanName,
-			trace.WithAttributes(
-				attribute.String("service.name", serviceName),
-				attribute.String("http.method", c.Method()),
-				attribute.String("http.url", c.OriginalURL()),
-				attribute.String("http.target", c.Path()),
-				attribute.String("http.client_ip", c.IP()),
-				attribute.String("http.user_agent", c.Get("User-Agent")),
-			),
+		// The matched route is not known until the router has run, so start with the
+		// method only and rename the span once c.Next has returned.
+		method := strings.Clone(c.Method())
+		ctx, span := tracer.Start(ctx, method,
+			trace.WithAttributes(attribute.String("service.name", serviceName)),
+			trace.WithAttributes(requestAttributes(c, o)...),
 			trace.WithSpanKind(trace.SpanKindServer),
 		)
 		defer span.End()
 
+		// A panic unwinds past the recording below: record it as the 500 that
+		// RecoveryMiddleware will send, then let the panic continue to it
+		defer func() {
+			if r := recover(); r != nil {
+				route := routePattern(c)
+				status := fiber.StatusInternalServerError
+				span.SetName(spanName(method, route))
+				span.SetAttributes(responseAttributes(route, status, errorType(nil, status), o)...)
+				err := panicError(r)
+				span.RecordError(err, trace.WithStackTrace(true))
+				span.SetStatus(codes.Error, err.Error())
+				panic(r)
+			}
+		}()
+
 		// Update context with span
 		// Fiber v3 handles context differently, we can use c.SetContext
 		c.SetContext(ctx)
@@ -44,13 +57,24 @@ func TracingMiddleware(serviceName string) fiber.Handler {
 		// Process request
 		err := c.Next()
 
-		// Recording response attributes
-		statusCode := c.Response().StatusCode()
-		span.SetAttributes(attribute.Int("http.status_code", statusCode))
+		// Recording response attributes with the status the ErrorHandler will send
+		route := routePattern(c)
+		status := statusCode(c, err)
+		errType := errorType(err, status)
+
+		span.SetName(spanName(method, route))
+		span.SetAttributes(responseAttributes(route, status, errType, o)...)
 
 		if err != nil {
 			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
+		}
+		// Server spans only report 5xx as errors; 4xx is the client's fault
+		if status >= fiber.StatusInternalServerError {
+			desc := errType
+			if err != nil {
+				desc = err.Error()
+			}
+			span.SetStatus(codes.Error, desc)
 		}
 
 		// Inject trace context into response headers
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when no user has the requested ID.
+	ErrNotFound = errors.New("user not found")
+	// ErrConflict is returned when a write would duplicate a unique field (email).
+	ErrConflict = errors.New("user already exists")
+)
+
+// User represents a user row.
+type User struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserRepository is the storage of users. Implementations must be safe for
+// concurrent use.
+type UserRepository interface {
+	// List returns up to limit users ordered by ID, skipping offset (a
+	// negative offset counts as 0).
+	List(ctx context.Context, limit, offset int) ([]User, error)
+	// Get returns the user with id, or ErrNotFound.
+	Get(ctx context.Context, id int) (User, error)
+	// Create stores a new user and returns it with ID and CreatedAt set.
+	Create(ctx context.Context, name, email string) (User, error)
+	// Update replaces name and email of u.ID and returns the stored user.
+	Update(ctx context.Context, u User) (User, error)
+	// Delete removes the user with id, or returns ErrNotFound.
+	Delete(ctx context.Context, id int) error
+
+	// WithTx runs fn against a repository bound to one transaction. The
+	// transaction commits if fn returns nil and rolls back otherwise. Calling
+	// WithTx on a transaction-bound repository nests (a savepoint in Postgres).
+	WithTx(ctx context.Context, fn func(tx UserRepository) error) error
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryUserRepository keeps users in a map. It is meant for tests and local
+// runs without Postgres, and enforces the same unique-email rule.
+type MemoryUserRepository struct {
+	// mu guards store; it is nil for the view handed to a WithTx callback,
+	// which is owned by that callback while the parent's lock is held.
+	mu    *sync.RWMutex
+	store *memoryStore
+}
+
+var _ UserRepository = (*MemoryUserRepository)(nil)
+
+type memoryStore struct {
+	users  map[int]User
+	nextID int
+}
+
+// NewMemoryUserRepository returns an empty repository.
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		mu:    &sync.RWMutex{},
+		store: &memoryStore{users: make(map[int]User), nextID: 1},
+	}
+}
+
+func (r *MemoryUserRepository) List(_ context.Context, limit, offset int) ([]User, error) {
+	r.rlock()
+	defer r.runlock()
+
+	ids := slices.Sorted(maps.Keys(r.store.users))
+	users := make([]User, 0, max(limit, 0))
+	for _, id := range ids[min(max(offset, 0), len(ids)):] {
+		if len(users) == limit {
+			break
+		}
+		users = append(users, r.store.users[id])
+	}
+	return users, nil
+}
+
+func (r *MemoryUserRepository) Get(_ context.Context, id int) (User, error) {
+	r.rlock()
+	defer r.runlock()
+
+	u, ok := r.store.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return u, nil
+}
+
+func (r *MemoryUserRepository) Create(_ context.Context, name, email string) (User, error) {
+	r.lock()
+	defer r.unlock()
+
+	if r.store.emailTaken(email, 0) {
+		return User{}, ErrConflict
+	}
+	u := User{ID: r.store.nextID, Name: name, Email: email, CreatedAt: time.Now().UTC()}
+	r.store.users[u.ID] = u
+	r.store.nextID++
+	return u, nil
+}
+
+func (r *MemoryUserRepository) Update(_ context.Context, u User) (User, error) {
+	r.lock()
+	defer r.unlock()
+
+	stored, ok := r.store.users[u.ID]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	if r.store.emailTaken(u.Email, u.ID) {
+		return User{}, ErrConflict
+	}
+	stored.Name, stored.Email = u.Name, u.Email
+	r.store.users[u.ID] = stored
+	return stored, nil
+}
+
+func (r *MemoryUserRepository) Delete(_ context.Context, id int) error {
+	r.lock()
+	defer r.unlock()
+
+	if _, ok := r.store.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.store.users, id)
+	return nil
+}
+
+// WithTx runs fn on a copy of the data and swaps it in when fn succeeds.
+// Transactions are serialized: the write lock is held for the whole callback.
+func (r *MemoryUserRepository) WithTx(_ context.Context, fn func(tx UserRepository) error) error {
+	r.lock()
+	defer r.unlock()
+
+	snapshot := &memoryStore{users: maps.Clone(r.store.users), nextID: r.store.nextID}
+	if err := fn(&MemoryUserRepository{store: snapshot}); err != nil {
+		return err
+	}
+	*r.store = *snapshot
+	return nil
+}
+
+func (s *memoryStore) emailTaken(email string, exceptID int) bool {
+	for id, u := range s.users {
+		if id != exceptID && u.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryUserRepository) lock() {
+	if r.mu != nil {
+		r.mu.Lock()
+	}
+}
+
+func (r *MemoryUserRepository) unlock() {
+	if r.mu != nil {
+		r.mu.Unlock()
+	}
+}
+
+func (r *MemoryUserRepository) rlock() {
+	if r.mu != nil {
+		r.mu.RLock()
+	}
+}
+
+func (r *MemoryUserRepository) runlock() {
+	if r.mu != nil {
+		r.mu.RUnlock()
+	}
+}
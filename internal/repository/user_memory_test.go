@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func seedMemory(t *testing.T, names ...string) *MemoryUserRepository {
+	t.Helper()
+	repo := NewMemoryUserRepository()
+	for _, name := range names {
+		if _, err := repo.Create(context.Background(), name, name+"@example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func TestMemoryCRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := seedMemory(t, "ada")
+
+	u, err := repo.Get(ctx, 1)
+	if err != nil || u.Name != "ada" {
+		t.Fatalf("Get = %+v, %v", u, err)
+	}
+	if _, err := repo.Create(ctx, "other", "ada@example.com"); !errors.Is(err, ErrConflict) {
+		t.Errorf("duplicate Create err = %v, want ErrConflict", err)
+	}
+
+	u.Name = "Ada"
+	if u, err = repo.Update(ctx, u); err != nil || u.Name != "Ada" {
+		t.Errorf("Update = %+v, %v", u, err)
+	}
+	if _, err := repo.Update(ctx, User{ID: 9}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update of a missing user err = %v, want ErrNotFound", err)
+	}
+
+	if err := repo.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Get(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete err = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryList(t *testing.T) {
+	repo := seedMemory(t, "a", "b", "c")
+	tests := []struct {
+		limit, offset int
+		want          []int
+	}{
+		{10, 0, []int{1, 2, 3}},
+		{2, 0, []int{1, 2}},
+		{2, 2, []int{3}},
+		{2, 5, nil},
+		{2, -1, []int{1, 2}},
+		{0, 0, nil},
+	}
+	for _, tt := range tests {
+		users, err := repo.List(context.Background(), tt.limit, tt.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, u := range users {
+			ids = append(ids, u.ID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("List(%d, %d) = %v, want %v", tt.limit, tt.offset, ids, tt.want)
+		}
+	}
+}
+
+func TestMemoryWithTx(t *testing.T) {
+	ctx := context.Background()
+	repo := seedMemory(t, "a")
+	boom := errors.New("boom")
+
+	err := repo.WithTx(ctx, func(tx UserRepository) error {
+		if _, err := tx.Create(ctx, "b", "b@example.com"); err != nil {
+			return err
+		}
+		// A failed nested transaction leaves the outer one's writes alone
+		if err := tx.WithTx(ctx, func(inner UserRepository) error {
+			_ = inner.Delete(ctx, 1)
+			return boom
+		}); !errors.Is(err, boom) {
+			t.Errorf("inner err = %v, want %v", err, boom)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users, _ := repo.List(ctx, 10, 0); len(users) != 2 {
+		t.Errorf("got %d users after commit, want 2", len(users))
+	}
+
+	err = repo.WithTx(ctx, func(tx UserRepository) error {
+		_ = tx.Delete(ctx, 1)
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	if _, err := repo.Get(ctx, 1); err != nil {
+		t.Errorf("user deleted by a rolled-back transaction: %v", err)
+	}
+}
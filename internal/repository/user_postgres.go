@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gofiberobservability/pkg/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DBTX is the part of *pgxpool.Pool and pgx.Tx the repository needs, so the
+// same code runs on the pool or inside a transaction.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// PostgresUserRepository stores users in the users table.
+type PostgresUserRepository struct {
+	db DBTX
+	// read picks where read-only queries run; writes always use db.
+	read func(ctx context.Context) DBTX
+}
+
+var _ UserRepository = (*PostgresUserRepository)(nil)
+
+// NewPostgresUserRepository returns a repository running its queries on db.
+func NewPostgresUserRepository(db DBTX) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db, read: func(context.Context) DBTX { return db }}
+}
+
+// NewReplicatedUserRepository returns a repository that writes to primary and
+// reads through database.Reader, i.e. from a replica when one is healthy.
+// Writes are marked on the context so read-your-writes scopes see them.
+func NewReplicatedUserRepository(primary DBTX) *PostgresUserRepository {
+	return &PostgresUserRepository{db: primary, read: func(ctx context.Context) DBTX { return database.Reader(ctx) }}
+}
+
+func (r *PostgresUserRepository) List(ctx context.Context, limit, offset int) ([]User, error) {
+	rows, err := r.read(ctx).Query(database.WithOperation(ctx, "users.list"),
+		"SELECT id, name, email, created_at FROM users ORDER BY id LIMIT $1 OFFSET $2",
+		limit, max(offset, 0),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	users, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (User, error) {
+		return scanUser(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan users: %w", err)
+	}
+	return users, nil
+}
+
+func (r *PostgresUserRepository) Get(ctx context.Context, id int) (User, error) {
+	row := r.read(ctx).QueryRow(database.WithOperation(ctx, "users.get"),
+		"SELECT id, name, email, created_at FROM users WHERE id = $1", id,
+	)
+	u, err := scanUser(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return User{}, ErrNotFound
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("failed to get user %d: %w", id, err)
+	}
+	return u, nil
+}
+
+func (r *PostgresUserRepository) Create(ctx context.Context, name, email string) (User, error) {
+	row := r.db.QueryRow(database.WithOperation(ctx, "users.insert"),
+		"INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id, name, email, created_at",
+		name, email,
+	)
+	u, err := scanUser(row)
+	database.MarkWrite(ctx)
+	if isUniqueViolation(err) {
+		return User{}, ErrConflict
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+	return u, nil
+}
+
+func (r *PostgresUserRepository) Update(ctx context.Context, u User) (User, error) {
+	row := r.db.QueryRow(database.WithOperation(ctx, "users.update"),
+		"UPDATE users SET name = $2, email = $3 WHERE id = $1 RETURNING id, name, email, created_at",
+		u.ID, u.Name, u.Email,
+	)
+	updated, err := scanUser(row)
+	database.MarkWrite(ctx)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return User{}, ErrNotFound
+	case isUniqueViolation(err):
+		return User{}, ErrConflict
+	case err != nil:
+		return User{}, fmt.Errorf("failed to update user %d: %w", u.ID, err)
+	}
+	return updated, nil
+}
+
+func (r *PostgresUserRepository) Delete(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(database.WithOperation(ctx, "users.delete"), "DELETE FROM users WHERE id = $1", id)
+	database.MarkWrite(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete user %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// WithTx runs fn through database.WithTx on r's db: a transaction (traced,
+// measured and retried on serialization failures), or a savepoint when r is
+// already bound to one. Reads inside the transaction run on it too.
+func (r *PostgresUserRepository) WithTx(ctx context.Context, fn func(tx UserRepository) error) error {
+	database.MarkWrite(ctx)
+	return database.WithTx(ctx, database.TxOptions{Name: "users", DB: r.db}, func(_ context.Context, tx pgx.Tx) error {
+		return fn(NewPostgresUserRepository(tx))
+	})
+}
+
+func scanUser(row pgx.Row) (User, error) {
+	var u User
+	err := row.Scan(&u.ID, &u.Name, &u.Email, &u.CreatedAt)
+	return u, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
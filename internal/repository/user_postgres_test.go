@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeDB is a DBTX that answers every QueryRow with row and every Exec with
+// tag, and records the transactions begun on it.
+type fakeDB struct {
+	row     fakeRow
+	tag     pgconn.CommandTag
+	args    []any
+	queries int
+	txs     []*fakeTx
+}
+
+func (db *fakeDB) Exec(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+	db.queries++
+	db.args = args
+	return db.tag, db.row.err
+}
+
+func (db *fakeDB) Query(_ context.Context, _ string, args ...any) (pgx.Rows, error) {
+	db.queries++
+	db.args = args
+	return nil, errors.New("query not supported by fakeDB")
+}
+
+func (db *fakeDB) QueryRow(_ context.Context, _ string, args ...any) pgx.Row {
+	db.queries++
+	db.args = args
+	return db.row
+}
+
+func (db *fakeDB) Begin(context.Context) (pgx.Tx, error) {
+	tx := &fakeTx{fakeDB: fakeDB{row: db.row, tag: db.tag}}
+	db.txs = append(db.txs, tx)
+	return tx, nil
+}
+
+// fakeTx is a transaction (or savepoint) on a fakeDB. The embedded pgx.Tx is
+// nil: methods the repository doesn't use panic.
+type fakeTx struct {
+	pgx.Tx
+	fakeDB
+	committed, rolledBack bool
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return tx.fakeDB.Exec(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return tx.fakeDB.Query(ctx, sql, args...)
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return tx.fakeDB.QueryRow(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return tx.fakeDB.Begin(ctx)
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+// fakeRow scans u, or fails with err.
+type fakeRow struct {
+	u   User
+	err error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*int) = r.u.ID
+	*dest[1].(*string) = r.u.Name
+	*dest[2].(*string) = r.u.Email
+	*dest[3].(*time.Time) = r.u.CreatedAt
+	return nil
+}
+
+func TestPostgresWithTxBeginsOnInjectedDB(t *testing.T) {
+	ada := User{ID: 1, Name: "Ada", Email: "ada@example.com"}
+	db := &fakeDB{row: fakeRow{u: ada}}
+	repo := NewPostgresUserRepository(db)
+
+	err := repo.WithTx(context.Background(), func(tx UserRepository) error {
+		_, err := tx.Create(context.Background(), ada.Name, ada.Email)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(db.txs) != 1 {
+		t.Fatalf("began %d transactions on the injected db, want 1", len(db.txs))
+	}
+	tx := db.txs[0]
+	if !tx.committed || tx.rolledBack {
+		t.Errorf("committed=%v rolledBack=%v, want a commit", tx.committed, tx.rolledBack)
+	}
+	if db.queries != 0 || tx.queries != 1 {
+		t.Errorf("queries on db=%d tx=%d, want the insert inside the transaction", db.queries, tx.queries)
+	}
+}
+
+func TestPostgresWithTxRollsBackOnError(t *testing.T) {
+	db := &fakeDB{}
+	boom := errors.New("boom")
+
+	err := NewPostgresUserRepository(db).WithTx(context.Background(), func(UserRepository) error {
+		return boom
+	})
+
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	if tx := db.txs[0]; tx.committed || !tx.rolledBack {
+		t.Errorf("committed=%v rolledBack=%v, want a rollback", tx.committed, tx.rolledBack)
+	}
+}
+
+func TestPostgresWithTxNestsSavepoint(t *testing.T) {
+	db := &fakeDB{}
+	boom := errors.New("boom")
+
+	err := NewPostgresUserRepository(db).WithTx(context.Background(), func(tx UserRepository) error {
+		if err := tx.WithTx(context.Background(), func(UserRepository) error { return boom }); !errors.Is(err, boom) {
+			t.Errorf("inner err = %v, want %v", err, boom)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(db.txs) != 1 {
+		t.Fatalf("began %d transactions on the db, want 1", len(db.txs))
+	}
+	outer := db.txs[0]
+	if len(outer.txs) != 1 {
+		t.Fatalf("began %d savepoints in the transaction, want 1", len(outer.txs))
+	}
+	if sp := outer.txs[0]; !sp.rolledBack {
+		t.Error("savepoint not rolled back after the inner error")
+	}
+	if !outer.committed {
+		t.Error("outer transaction not committed")
+	}
+}
+
+func TestPostgresErrors(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		db   *fakeDB
+		call func(r *PostgresUserRepository) error
+		want error
+	}{
+		{"get missing", &fakeDB{row: fakeRow{err: pgx.ErrNoRows}}, func(r *PostgresUserRepository) error {
+			_, err := r.Get(ctx, 1)
+			return err
+		}, ErrNotFound},
+		{"create duplicate", &fakeDB{row: fakeRow{err: &pgconn.PgError{Code: "23505"}}}, func(r *PostgresUserRepository) error {
+			_, err := r.Create(ctx, "Ada", "ada@example.com")
+			return err
+		}, ErrConflict},
+		{"update missing", &fakeDB{row: fakeRow{err: pgx.ErrNoRows}}, func(r *PostgresUserRepository) error {
+			_, err := r.Update(ctx, User{ID: 1})
+			return err
+		}, ErrNotFound},
+		{"delete missing", &fakeDB{tag: pgconn.NewCommandTag("DELETE 0")}, func(r *PostgresUserRepository) error {
+			return r.Delete(ctx, 1)
+		}, ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(NewPostgresUserRepository(tt.db)); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostgresListClampsOffset(t *testing.T) {
+	db := &fakeDB{}
+	_, _ = NewPostgresUserRepository(db).List(context.Background(), 10, -5)
+
+	if len(db.args) != 2 || db.args[1] != 0 {
+		t.Errorf("query args = %v, want offset 0", db.args)
+	}
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gofiberobservability/pkg/database"
+)
+
+// SQLiteDBTX is the part of *database.SQLiteDB and *database.SQLiteTx the
+// repository needs, so the same code runs on the database or in a transaction.
+type SQLiteDBTX interface {
+	Exec(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Query(ctx context.Context, query string, args ...any) (*database.SQLiteRows, error)
+	QueryRow(ctx context.Context, query string, args ...any) *database.SQLiteRow
+	Begin(ctx context.Context) (*database.SQLiteTx, error)
+}
+
+// SQLiteUserRepository stores users in the users table of a SQLite database.
+// It is meant for local development and tests without Postgres.
+type SQLiteUserRepository struct {
+	db SQLiteDBTX
+}
+
+var _ UserRepository = (*SQLiteUserRepository)(nil)
+
+// NewSQLiteUserRepository returns a repository running its queries on db.
+func NewSQLiteUserRepository(db SQLiteDBTX) *SQLiteUserRepository {
+	return &SQLiteUserRepository{db: db}
+}
+
+func (r *SQLiteUserRepository) List(ctx context.Context, limit, offset int) ([]User, error) {
+	rows, err := r.db.Query(database.WithOperation(ctx, "users.list"),
+		"SELECT id, name, email, created_at FROM users ORDER BY id LIMIT ? OFFSET ?",
+		limit, max(offset, 0),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		u, err := scanSQLiteUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan users: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan users: %w", err)
+	}
+	return users, nil
+}
+
+func (r *SQLiteUserRepository) Get(ctx context.Context, id int) (User, error) {
+	row := r.db.QueryRow(database.WithOperation(ctx, "users.get"),
+		"SELECT id, name, email, created_at FROM users WHERE id = ?", id,
+	)
+	u, err := scanSQLiteUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return User{}, ErrNotFound
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("failed to get user %d: %w", id, err)
+	}
+	return u, nil
+}
+
+func (r *SQLiteUserRepository) Create(ctx context.Context, name, email string) (User, error) {
+	row := r.db.QueryRow(database.WithOperation(ctx, "users.insert"),
+		"INSERT INTO users (name, email) VALUES (?, ?) RETURNING id, name, email, created_at",
+		name, email,
+	)
+	u, err := scanSQLiteUser(row)
+	if isSQLiteUniqueViolation(err) {
+		return User{}, ErrConflict
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+	return u, nil
+}
+
+func (r *SQLiteUserRepository) Update(ctx context.Context, u User) (User, error) {
+	row := r.db.QueryRow(database.WithOperation(ctx, "users.update"),
+		"UPDATE users SET name = ?, email = ? WHERE id = ? RETURNING id, name, email, created_at",
+		u.Name, u.Email, u.ID,
+	)
+	updated, err := scanSQLiteUser(row)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return User{}, ErrNotFound
+	case isSQLiteUniqueViolation(err):
+		return User{}, ErrConflict
+	case err != nil:
+		return User{}, fmt.Errorf("failed to update user %d: %w", u.ID, err)
+	}
+	return updated, nil
+}
+
+func (r *SQLiteUserRepository) Delete(ctx context.Context, id int) error {
+	res, err := r.db.Exec(database.WithOperation(ctx, "users.delete"), "DELETE FROM users WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user %d: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// WithTx begins a transaction, or a savepoint when r is already bound to one.
+func (r *SQLiteUserRepository) WithTx(ctx context.Context, fn func(tx UserRepository) error) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Rolls back on error or panic; a no-op after Commit
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	if err := fn(NewSQLiteUserRepository(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func scanSQLiteUser(row interface{ Scan(...any) error }) (User, error) {
+	var u User
+	err := row.Scan(&u.ID, &u.Name, &u.Email, sqliteTime{&u.CreatedAt})
+	return u, err
+}
+
+// sqliteTimeFormats are the layouts SQLite timestamps are stored in: the
+// column default, and what drivers write for time.Time arguments.
+var sqliteTimeFormats = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999-07:00",
+	time.RFC3339Nano,
+}
+
+// sqliteTime scans a timestamp column whether the driver returns a
+// time.Time (declared TIMESTAMP columns) or the stored text, as it may for
+// RETURNING clauses.
+type sqliteTime struct {
+	t *time.Time
+}
+
+func (s sqliteTime) Scan(src any) error {
+	var text string
+	switch v := src.(type) {
+	case time.Time:
+		*s.t = v
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("unsupported timestamp type %T", src)
+	}
+	for _, layout := range sqliteTimeFormats {
+		if t, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			*s.t = t
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported timestamp format %q", text)
+}
+
+// isSQLiteUniqueViolation matches SQLite's own message, which every driver
+// passes through (SQLITE_CONSTRAINT_UNIQUE).
+func isSQLiteUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -0,0 +1,171 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow while the breaker is rejecting calls.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the breaker state.
+type State int
+
+const (
+	// StateClosed lets every call through.
+	StateClosed State = iota
+	// StateHalfOpen lets a single probe call through after the open timeout.
+	StateHalfOpen
+	// StateOpen fails fast without calling the dependency.
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half_open"
+	case StateOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker is a consecutive-failure circuit breaker.
+// After Threshold consecutive failures it opens for OpenTimeout, then lets one
+// probe through: success closes it again, failure re-opens it.
+type Breaker struct {
+	name        string
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// New creates a breaker. A threshold <= 0 disables it (Allow always succeeds).
+func New(name string, threshold int, openTimeout time.Duration) *Breaker {
+	return &Breaker{
+		name:        name,
+		threshold:   threshold,
+		openTimeout: openTimeout,
+	}
+}
+
+// Name returns the dependency name the breaker guards.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state, moving open to half-open once the timeout elapsed.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(time.Now())
+	return b.state
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be followed
+// by exactly one Success, Failure or Ignore.
+func (b *Breaker) Allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(time.Now())
+
+	switch b.state {
+	case StateOpen:
+		return ErrOpen
+	case StateHalfOpen:
+		if b.probing {
+			return ErrOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Success records a successful call. Only a half-open breaker closes on it:
+// while open, a success is a late answer to a call allowed before the breaker
+// opened and doesn't let traffic through again.
+func (b *Breaker) Success() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(time.Now())
+
+	switch b.state {
+	case StateHalfOpen:
+		b.state = StateClosed
+		b.probing = false
+		b.failures = 0
+	case StateClosed:
+		b.failures = 0
+	}
+}
+
+// Failure records a failed call. Late failures while open don't extend the
+// open period.
+func (b *Breaker) Failure() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(time.Now())
+
+	switch b.state {
+	case StateHalfOpen:
+		b.state = StateOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	case StateClosed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.state = StateOpen
+			b.openedAt = time.Now()
+		}
+	}
+}
+
+// Ignore records a call whose outcome says nothing about the dependency,
+// such as one canceled by its caller. A half-open breaker lets the next
+// call probe instead.
+func (b *Breaker) Ignore() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Record is a convenience for Success/Failure based on err.
+func (b *Breaker) Record(err error) {
+	if err != nil {
+		b.Failure()
+		return
+	}
+	b.Success()
+}
+
+func (b *Breaker) advance(now time.Time) {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.openTimeout {
+		b.state = StateHalfOpen
+		b.probing = false
+	}
+}
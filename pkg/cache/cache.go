@@ -0,0 +1,358 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gofiberobservability/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// Outcome is how GetOrLoad answered a lookup.
+type Outcome string
+
+const (
+	// OutcomeHit is a fresh value from the cache.
+	OutcomeHit Outcome = "hit"
+	// OutcomeStale is an expired value served while a background load refreshes it.
+	OutcomeStale Outcome = "stale"
+	// OutcomeMiss is a value loaded (and cached) by this call.
+	OutcomeMiss Outcome = "miss"
+	// OutcomeCoalesced is a value loaded by a concurrent call for the same key.
+	OutcomeCoalesced Outcome = "coalesced"
+	// OutcomeNotFound is a load that failed with Options.NotFound.
+	OutcomeNotFound Outcome = "not_found"
+	// OutcomeError is a failed load.
+	OutcomeError Outcome = "error"
+)
+
+const (
+	defaultLoadTimeout = 5 * time.Second
+
+	// envelopeVersion starts every stored value; see encodeEnvelope.
+	envelopeVersion byte = 1
+	envelopeHeader       = 9
+
+	nameKey      = attribute.Key("cache.name")
+	outcomeKey   = attribute.Key("cache.outcome")
+	operationKey = attribute.Key("cache.operation")
+)
+
+var tracer = otel.Tracer("gofiberobservability/cache")
+
+// Options configures a Cache.
+type Options struct {
+	// TTL is how long a value stays fresh; <= 0 disables storing values.
+	TTL time.Duration
+	// Jitter spreads each TTL by up to ±Jitter×TTL (0.1 = ±10%), so keys
+	// cached together don't all expire together.
+	Jitter float64
+	// StaleTTL keeps values this long past TTL. A stale value is returned
+	// right away while a single background load refreshes it (0 disables).
+	StaleTTL time.Duration
+	// Codec serializes values; nil means JSON.
+	Codec Codec
+	// NotFound is the load error meaning the value doesn't exist (e.g.
+	// repository.ErrNotFound). It is not cached either, but reports
+	// OutcomeNotFound and doesn't mark spans as failed.
+	NotFound error
+	// Bus, if set, carries Invalidate calls to the caches of the same name on
+	// other instances.
+	Bus *Bus
+	// LoadTimeout bounds loads. They run detached from the caller's
+	// cancellation, so one canceled request doesn't fail the others waiting
+	// on the same load; the canceled caller itself returns right away. 0
+	// means 5s.
+	LoadTimeout time.Duration
+	// RedeleteDelay is how long after Invalidate the keys are deleted from
+	// Redis a second time; see Invalidate. 0 means LoadTimeout + 1s, so no
+	// load that read the old data can store it afterwards; < 0 disables it.
+	RedeleteDelay time.Duration
+}
+
+// Cache is a cache-aside layer over Redis. Concurrent misses for a key in
+// this process share a single load, so a hot key expiring doesn't send
+// every request to the database at once.
+type Cache struct {
+	name  string
+	rdb   redis.Cmdable
+	opts  Options
+	group singleflight.Group
+	m     *cacheMetrics
+
+	// epoch counts invalidations; a load that spans one doesn't store its result
+	epoch atomic.Uint64
+
+	// redeletes are the pending redelete timers, stopped by Close
+	mu        sync.Mutex
+	closed    bool
+	redeletes map[*time.Timer]struct{}
+	running   sync.WaitGroup
+}
+
+// New returns a cache labeled name in spans and metrics (cache.name). With a
+// nil rdb nothing is stored: every lookup loads, still de-duplicated.
+func New(name string, rdb redis.Cmdable, opts Options) *Cache {
+	if opts.Codec == nil {
+		opts.Codec = JSON
+	}
+	if opts.LoadTimeout <= 0 {
+		opts.LoadTimeout = defaultLoadTimeout
+	}
+	if opts.RedeleteDelay == 0 {
+		opts.RedeleteDelay = opts.LoadTimeout + time.Second
+	}
+	opts.Jitter = min(max(opts.Jitter, 0), 1)
+	if opts.TTL <= 0 {
+		rdb = nil
+	}
+	c := &Cache{name: name, rdb: rdb, opts: opts, m: getCacheMetrics(), redeletes: make(map[*time.Timer]struct{})}
+	if opts.Bus != nil {
+		opts.Bus.register(c)
+	}
+	return c
+}
+
+// Name returns the cache's label.
+func (c *Cache) Name() string {
+	return c.name
+}
+
+// Close stops the pending redeletes and waits for those already running, so
+// none reaches Redis after shutdown. Invalidate still deletes keys once.
+func (c *Cache) Close() {
+	c.mu.Lock()
+	c.closed = true
+	for t := range c.redeletes {
+		if t.Stop() {
+			c.running.Done()
+		}
+		delete(c.redeletes, t)
+	}
+	c.mu.Unlock()
+	c.running.Wait()
+}
+
+// GetOrLoad returns the value cached under key, or calls load and caches its
+// result. Errors from load are returned as is and not cached. Redis failures
+// are logged and counted, and fall back to load.
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, load func(ctx context.Context) (T, error)) (T, Outcome, error) {
+	ctx, span := tracer.Start(ctx, "cache.get_or_load "+c.name, trace.WithAttributes(
+		nameKey.String(c.name),
+		attribute.String("cache.key", key),
+		attribute.String("cache.codec", c.opts.Codec.Name()),
+	))
+	defer span.End()
+
+	v, outcome, err := getOrLoad(ctx, c, key, load)
+
+	span.SetAttributes(outcomeKey.String(string(outcome)))
+	if outcome == OutcomeError {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	c.m.requests.Add(ctx, 1, metric.WithAttributes(nameKey.String(c.name), outcomeKey.String(string(outcome))))
+	return v, outcome, err
+}
+
+func getOrLoad[T any](ctx context.Context, c *Cache, key string, load func(ctx context.Context) (T, error)) (T, Outcome, error) {
+	if v, freshUntil, ok := get[T](ctx, c, key); ok {
+		if time.Now().Before(freshUntil) {
+			return v, OutcomeHit, nil
+		}
+		refresh(ctx, c, key, load)
+		return v, OutcomeStale, nil
+	}
+
+	leader := false
+	ch := c.group.DoChan(key, func() (any, error) {
+		leader = true
+		return loadAndStore(context.WithoutCancel(ctx), c, key, load)
+	})
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		// The load carries on for the other callers and still stores its result
+		var zero T
+		return zero, OutcomeError, ctx.Err()
+	}
+	v, _ := res.Val.(T)
+	switch err := res.Err; {
+	case c.notFound(err):
+		return v, OutcomeNotFound, err
+	case err != nil:
+		return v, OutcomeError, err
+	case !leader:
+		return v, OutcomeCoalesced, nil
+	}
+	return v, OutcomeMiss, nil
+}
+
+// refresh reloads a stale key in the background, once per key at a time. The
+// load outlives the request, so its span starts a new trace linked to the
+// request that found the stale value.
+func refresh[T any](ctx context.Context, c *Cache, key string, load func(ctx context.Context) (T, error)) {
+	link := trace.LinkFromContext(ctx)
+	c.group.DoChan(key, func() (any, error) {
+		ctx, span := tracer.Start(context.Background(), "cache.refresh "+c.name,
+			trace.WithLinks(link),
+			trace.WithAttributes(nameKey.String(c.name), attribute.String("cache.key", key)),
+		)
+		defer span.End()
+
+		v, err := loadAndStore(ctx, c, key, load)
+		if err != nil && !c.notFound(err) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logger.GetLoggerWithTraceContext(ctx).Warn("Cache refresh failed",
+				zap.String("cache.name", c.name), zap.String("cache.key", key), zap.Error(err))
+		}
+		return v, err
+	})
+}
+
+// loadAndStore calls load under LoadTimeout and caches a successful result,
+// unless keys were invalidated meanwhile: the result may predate the change.
+func loadAndStore[T any](ctx context.Context, c *Cache, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	epoch := c.epoch.Load()
+	ctx, cancel := context.WithTimeout(ctx, c.opts.LoadTimeout)
+	defer cancel()
+
+	ctx, span := tracer.Start(ctx, "cache.load "+c.name, trace.WithAttributes(nameKey.String(c.name)))
+	defer span.End()
+
+	start := time.Now()
+	v, err := load(ctx)
+
+	attrs := []attribute.KeyValue{nameKey.String(c.name)}
+	if c.notFound(err) {
+		span.SetAttributes(outcomeKey.String(string(OutcomeNotFound)))
+	} else if err != nil {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(loadErrorType(err)))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	c.m.loadDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+	if err != nil {
+		return v, err
+	}
+
+	if c.epoch.Load() != epoch {
+		span.SetAttributes(attribute.Bool("cache.store_skipped", true))
+		return v, nil
+	}
+	c.set(ctx, key, v)
+	return v, nil
+}
+
+// get reads and decodes key. ok is false on a miss, a Redis error or an
+// entry that doesn't decode (which is then overwritten by the load).
+func get[T any](ctx context.Context, c *Cache, key string) (v T, freshUntil time.Time, ok bool) {
+	if c.rdb == nil {
+		return v, freshUntil, false
+	}
+
+	data, err := c.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return v, freshUntil, false
+	}
+	if err != nil {
+		c.fail(ctx, "get", key, err)
+		return v, freshUntil, false
+	}
+
+	freshUntil, payload, err := decodeEnvelope(data)
+	if err == nil {
+		err = c.opts.Codec.Unmarshal(payload, &v)
+	}
+	if err != nil {
+		c.fail(ctx, "decode", key, err)
+		var zero T
+		return zero, time.Time{}, false
+	}
+	return v, freshUntil, true
+}
+
+// set stores v under key with a jittered TTL plus the stale window.
+func (c *Cache) set(ctx context.Context, key string, v any) {
+	if c.rdb == nil {
+		return
+	}
+
+	payload, err := c.opts.Codec.Marshal(v)
+	if err != nil {
+		c.fail(ctx, "encode", key, err)
+		return
+	}
+	ttl := c.ttl()
+	if err := c.rdb.Set(ctx, key, encodeEnvelope(time.Now().Add(ttl), payload), ttl+c.opts.StaleTTL).Err(); err != nil {
+		c.fail(ctx, "set", key, err)
+	}
+}
+
+func (c *Cache) notFound(err error) bool {
+	return err != nil && c.opts.NotFound != nil && errors.Is(err, c.opts.NotFound)
+}
+
+func (c *Cache) ttl() time.Duration {
+	if c.opts.Jitter == 0 {
+		return c.opts.TTL
+	}
+	spread := float64(c.opts.TTL) * c.opts.Jitter
+	return c.opts.TTL + time.Duration((rand.Float64()*2-1)*spread)
+}
+
+// fail records a cache store error. The caller carries on without the cache.
+func (c *Cache) fail(ctx context.Context, operation, key string, err error) {
+	c.m.errors.Add(ctx, 1, metric.WithAttributes(nameKey.String(c.name), operationKey.String(operation)))
+	logger.GetLoggerWithTraceContext(ctx).Warn("Cache operation failed",
+		zap.String("cache.name", c.name),
+		zap.String("cache.operation", operation),
+		zap.String("cache.key", key),
+		zap.Error(err),
+	)
+}
+
+// encodeEnvelope prefixes the payload with a version byte and the time the
+// value stops being fresh (Unix milliseconds), so staleness survives in
+// Redis independently of the key's TTL.
+func encodeEnvelope(freshUntil time.Time, payload []byte) []byte {
+	buf := make([]byte, envelopeHeader, envelopeHeader+len(payload))
+	buf[0] = envelopeVersion
+	binary.BigEndian.PutUint64(buf[1:], uint64(freshUntil.UnixMilli()))
+	return append(buf, payload...)
+}
+
+// decodeEnvelope rejects values in any other format, e.g. plain JSON written
+// before this package existed.
+func decodeEnvelope(data []byte) (time.Time, []byte, error) {
+	if len(data) < envelopeHeader || data[0] != envelopeVersion {
+		return time.Time{}, nil, errors.New("unknown cache entry format")
+	}
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(data[1:envelopeHeader]))), data[envelopeHeader:], nil
+}
+
+func loadErrorType(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	return "load"
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec serializes cached values.
+type Codec interface {
+	// Name labels spans (cache.codec) and selects the codec in config.
+	Name() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSON is readable with redis-cli; the default.
+	JSON Codec = jsonCodec{}
+	// MsgPack is smaller and faster to decode than JSON.
+	MsgPack Codec = msgpackCodec{}
+)
+
+// CodecByName returns JSON for "json" (or "") and MsgPack for "msgpack".
+func CodecByName(name string) (Codec, bool) {
+	switch name {
+	case "", JSON.Name():
+		return JSON, true
+	case MsgPack.Name():
+		return MsgPack, true
+	}
+	return nil, false
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                       { return "json" }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string                       { return "msgpack" }
+func (msgpackCodec) Marshal(v any) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
@@ -0,0 +1,259 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gofiberobservability/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	sourceKey = attribute.Key("cache.invalidation.source")
+
+	sourceLocal  = "local"
+	sourceRemote = "remote"
+)
+
+// Invalidate removes keys whose source data changed: from Redis, from loads
+// in flight in this process (which then don't store what they read), and,
+// through Options.Bus, from every other instance. Failures are logged and
+// counted like other store errors.
+//
+// A load can still store data read before the change: on another instance
+// before the bus message arrives, or here between its epoch check and SET.
+// The keys are therefore deleted again after Options.RedeleteDelay, which
+// bounds such a stale entry to that delay instead of the whole TTL. What
+// remains is a redelete that fails, or a load whose SET reaches Redis later
+// than the delay.
+func (c *Cache) Invalidate(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	ctx, span := tracer.Start(ctx, "cache.invalidate "+c.name, trace.WithAttributes(
+		nameKey.String(c.name),
+		attribute.StringSlice("cache.keys", keys),
+	))
+	defer span.End()
+
+	// Evict first: a load finishing after the DEL must see the new epoch
+	c.evict(ctx, keys, sourceLocal)
+
+	if c.rdb != nil {
+		if err := c.rdb.Del(ctx, keys...).Err(); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			c.fail(ctx, "delete", strings.Join(keys, ","), err)
+		}
+		c.redelete(ctx, keys)
+	}
+	if c.opts.Bus != nil {
+		if err := c.opts.Bus.publish(ctx, c.name, keys); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			c.fail(ctx, "publish", strings.Join(keys, ","), err)
+		}
+	}
+}
+
+// redelete deletes keys from Redis once more after RedeleteDelay, unless the
+// cache is closed first. It outlives the request, so its span starts a new
+// trace linked to the invalidation.
+func (c *Cache) redelete(ctx context.Context, keys []string) {
+	if c.opts.RedeleteDelay < 0 {
+		return
+	}
+	link := trace.LinkFromContext(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.running.Add(1)
+	var t *time.Timer
+	t = time.AfterFunc(c.opts.RedeleteDelay, func() {
+		defer c.running.Done()
+		c.mu.Lock()
+		delete(c.redeletes, t)
+		c.mu.Unlock()
+
+		ctx, span := tracer.Start(context.Background(), "cache.redelete "+c.name,
+			trace.WithLinks(link),
+			trace.WithAttributes(nameKey.String(c.name), attribute.StringSlice("cache.keys", keys)),
+		)
+		defer span.End()
+
+		ctx, cancel := context.WithTimeout(ctx, c.opts.LoadTimeout)
+		defer cancel()
+		if err := c.rdb.Del(ctx, keys...).Err(); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			c.fail(ctx, "redelete", strings.Join(keys, ","), err)
+		}
+	})
+	c.redeletes[t] = struct{}{}
+}
+
+// evict drops this process's state for keys. Loads already running for them
+// read the old data, so they are forgotten (later lookups start a new load)
+// and the epoch bump stops them from storing their result.
+func (c *Cache) evict(ctx context.Context, keys []string, source string) {
+	c.epoch.Add(1)
+	for _, key := range keys {
+		c.group.Forget(key)
+	}
+	c.m.invalidations.Add(ctx, int64(len(keys)), metric.WithAttributes(nameKey.String(c.name), sourceKey.String(source)))
+}
+
+// invalidation is the pub/sub message. Trace carries the W3C context of the
+// invalidating request, which receivers link to.
+type invalidation struct {
+	Origin string            `json:"origin"`
+	Cache  string            `json:"cache"`
+	Keys   []string          `json:"keys"`
+	Trace  map[string]string `json:"trace,omitempty"`
+}
+
+// Bus broadcasts invalidations between instances over a Redis pub/sub
+// channel. Every cache created with it evicts the keys other instances
+// invalidate; an instance ignores its own messages.
+type Bus struct {
+	rdb     redis.UniversalClient
+	channel string
+	origin  string
+
+	mu     sync.RWMutex
+	caches map[string]*Cache
+
+	sub  *redis.PubSub
+	done sync.WaitGroup
+}
+
+// NewBus returns a bus on channel. Caches join it through Options.Bus, and
+// it receives once Start is called.
+func NewBus(rdb redis.UniversalClient, channel string) *Bus {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return &Bus{
+		rdb:     rdb,
+		channel: channel,
+		origin:  hex.EncodeToString(id),
+		caches:  make(map[string]*Cache),
+	}
+}
+
+func (b *Bus) register(c *Cache) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.caches[c.name] = c
+}
+
+// Start subscribes to the channel and handles messages in the background
+// until Close. go-redis resubscribes on its own after connection errors.
+func (b *Bus) Start(ctx context.Context, log *zap.Logger) error {
+	sub := b.rdb.Subscribe(ctx, b.channel)
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return fmt.Errorf("failed to subscribe to %s: %w", b.channel, err)
+	}
+	b.sub = sub
+
+	b.done.Add(1)
+	go func() {
+		defer b.done.Done()
+		for msg := range sub.Channel() {
+			b.receive(msg.Payload)
+		}
+	}()
+
+	log.Info("Cache invalidation bus started", zap.String("channel", b.channel))
+	return nil
+}
+
+// Close unsubscribes and waits for the message in progress.
+func (b *Bus) Close() error {
+	if b.sub == nil {
+		return nil
+	}
+	err := b.sub.Close()
+	b.done.Wait()
+	return err
+}
+
+func (b *Bus) publish(ctx context.Context, cacheName string, keys []string) error {
+	msg := invalidation{Origin: b.origin, Cache: cacheName, Keys: keys, Trace: map[string]string{}}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Trace))
+
+	ctx, span := tracer.Start(ctx, "publish "+b.channel, trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(b.messagingAttrs()...))
+	defer span.End()
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := b.rdb.Publish(ctx, b.channel, payload).Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+// receive evicts the keys of a message from another instance. Its span starts
+// a new trace linked to the request that invalidated them.
+func (b *Bus) receive(payload string) {
+	var msg invalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		logger.GetLogger().Warn("Invalid cache invalidation message",
+			zap.String("channel", b.channel), zap.Error(err))
+		return
+	}
+	if msg.Origin == b.origin {
+		return
+	}
+
+	b.mu.RLock()
+	c := b.caches[msg.Cache]
+	b.mu.RUnlock()
+	if c == nil {
+		return
+	}
+
+	remote := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(msg.Trace))
+	ctx, span := tracer.Start(context.Background(), "cache.invalidate.receive "+c.name,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(trace.LinkFromContext(remote)),
+		trace.WithAttributes(append(b.messagingAttrs(),
+			nameKey.String(c.name),
+			attribute.StringSlice("cache.keys", msg.Keys),
+		)...),
+	)
+	defer span.End()
+
+	c.evict(ctx, msg.Keys, sourceRemote)
+}
+
+func (b *Bus) messagingAttrs() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("redis"),
+		semconv.MessagingDestinationName(b.channel),
+	}
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"sync"
+
+	"gofiberobservability/pkg/metrics"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+type cacheMetrics struct {
+	requests      metric.Int64Counter
+	loadDuration  metric.Float64Histogram
+	errors        metric.Int64Counter
+	invalidations metric.Int64Counter
+}
+
+var (
+	cacheMetricsOnce sync.Once
+	cacheMetricsInst *cacheMetrics
+)
+
+// getCacheMetrics lazily registers the cache instruments on first use; all
+// caches share them, labeled by cache.name.
+func getCacheMetrics() *cacheMetrics {
+	cacheMetricsOnce.Do(func() {
+		meter := metrics.GetMeter()
+		m := &cacheMetrics{}
+
+		m.requests, _ = meter.Int64Counter("cache.requests",
+			metric.WithDescription("Cache lookups by outcome (hit, stale, miss, coalesced, not_found, error)"),
+			metric.WithUnit("{request}"),
+		)
+		m.loadDuration, _ = meter.Float64Histogram("cache.load.duration",
+			metric.WithDescription("Duration of loads from the source of truth on a miss or refresh"),
+			metric.WithUnit("s"),
+			metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5),
+		)
+		m.errors, _ = meter.Int64Counter("cache.errors",
+			metric.WithDescription("Failed cache store operations, by operation (get, set, decode, encode, delete, publish)"),
+			metric.WithUnit("{error}"),
+		)
+
+		m.invalidations, _ = meter.Int64Counter("cache.invalidations",
+			metric.WithDescription("Keys invalidated, by source (local mutation or remote instance)"),
+			metric.WithUnit("{key}"),
+		)
+
+		cacheMetricsInst = m
+	})
+	return cacheMetricsInst
+}
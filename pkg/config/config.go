@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -36,12 +37,80 @@ type Config struct {
 	TraceSampleRate  float64 // 0.0 to 1.0 (0.1 = 10%, 1.0 = 100%)
 	TraceExportBatch int
 
+	// HTTP semantic-convention migration ("http" = stable only, "http/dup" = stable + legacy)
+	SemconvStabilityOptIn string
+
+	// HTTP telemetry exclusions (applied to tracing, logging and metrics)
+	HTTPExcludePaths     []string // path.Match globs
+	HTTPExcludePathRegex []string
+	HTTPExcludeMethods   []string
+
+	// HTTP metric cardinality guard
+	HTTPMetricsUnmatchedRoute      string // http.route value for requests no route matched
+	HTTPMetricsStatusClasses       bool   // record status codes as 2xx/4xx/5xx
+	HTTPMetricsAttributeValueLimit int    // distinct values per attribute before folding into "other" (0 = off)
+
+	// W3C baggage keys promoted to telemetry. Span/log keys are free-form;
+	// metric keys become series dimensions, so keep them low-cardinality.
+	BaggageSpanKeys   []string
+	BaggageLogKeys    []string
+	BaggageMetricKeys []string
+
+	// Metrics readers (both may run together on the same MeterProvider)
+	MetricsOTLPEnabled       bool
+	MetricsPrometheusEnabled bool
+	MetricsViewsFile         string // JSON file with histogram/attribute view definitions
+
+	// Metrics export tuning
+	MetricsExportInterval       time.Duration
+	MetricsExportTimeout        time.Duration
+	MetricsTemporality          string            // cumulative, delta or lowmemory (OTLP push only)
+	MetricsTemporalityOverrides map[string]string // instrument kind -> cumulative or delta
+	MetricsExemplarFilter       string            // always, trace_based or off
+	MetricsCardinalityLimit     int               // max series per instrument per collection (0 = unlimited)
+
+	// Service level objectives
+	SLOObjectivesFile string // JSON file with SLO objectives (empty = no SLO tracking)
+
 	// Server performance tuning
-	Prefork bool
+	Prefork    bool
+	AdminAddr  string // separate listener for /metrics and other operator endpoints ("" = main app)
+	AdminToken string // bearer token for admin-only views such as /health?verbose=1 ("" = disabled)
+
+	// Outbound HTTP client configuration
+	HTTPClientTimeout            time.Duration
+	HTTPClientHostTimeouts       map[string]time.Duration // host or host:port -> timeout
+	HTTPClientMaxRetries         int
+	HTTPClientRetryBackoff       time.Duration
+	HTTPClientRetryMaxBackoff    time.Duration
+	HTTPClientBreakerThreshold   int // consecutive failures before a host's breaker opens (0 = off)
+	HTTPClientBreakerOpenTimeout time.Duration
 
 	// Database configuration
-	DatabaseURL string
-	RedisURL    string
+	DatabaseURL                   string   // postgres:// or, for local development, sqlite:// / file:
+	DatabaseReplicaURLs           []string // read-only replicas; empty routes all reads to DatabaseURL
+	DatabaseReplicaHealthInterval time.Duration
+	DBStatementTimeout            time.Duration // default per-statement context deadline (0 = none)
+	DBServerStatementTimeout      time.Duration // server-side statement_timeout ceiling (0 = server default; keep 0 behind PgBouncer)
+	DBQueryExecMode               string        // cache_statement, cache_describe, describe_exec, exec or simple_protocol
+	DBSlowQueryThreshold          time.Duration // log queries slower than this (0 = off)
+	DBSlowQueryExplainRate        float64       // share of slow queries to EXPLAIN (0 = never)
+	RedisURL                      string        // empty disables Redis (SQLite mode without REDIS_URL/REDIS_HOST)
+
+	// Cache (Redis cache-aside for user lookups)
+	UserCacheTTL             time.Duration // how long a cached user stays fresh (0 = don't cache)
+	UserCacheStaleTTL        time.Duration // serve expired users this long while refreshing them (0 = off)
+	CacheTTLJitter           float64       // spread TTLs by ±this fraction so keys don't expire together
+	CacheCodec               string        // json or msgpack
+	CacheInvalidationChannel string        // Redis pub/sub channel for cross-instance invalidation
+	CacheRedeleteDelay       time.Duration // delete invalidated keys again after this (0 = load timeout + 1s, < 0 = off)
+
+	// Dependency resilience (Postgres and Redis)
+	StartupRetryTimeout          time.Duration // total time to wait for dependencies at startup
+	StartupRetryBackoff          time.Duration // first retry delay, doubled per attempt (0 = no retries)
+	StartupRetryMaxBackoff       time.Duration
+	DependencyBreakerThreshold   int // consecutive connection failures before a breaker opens (0 = off)
+	DependencyBreakerOpenTimeout time.Duration
 }
 
 // NewConfig creates a new configuration with defaults and environment overrides
@@ -63,8 +132,49 @@ func NewConfig() *Config {
 		TraceSampleRate:  getEnvFloat("OTEL_TRACE_SAMPLE_RATE", 1.0),
 		TraceExportBatch: getEnvInt("OTEL_TRACE_EXPORT_BATCH", 512),
 
+		SemconvStabilityOptIn: getEnv("OTEL_SEMCONV_STABILITY_OPT_IN", "http"),
+
+		HTTPExcludePaths:     getEnvList("OTEL_HTTP_EXCLUDE_PATHS", []string{"/health", "/healthz", "/livez", "/readyz", "/favicon.ico", "/metrics"}),
+		HTTPExcludePathRegex: getEnvList("OTEL_HTTP_EXCLUDE_PATH_REGEX", nil),
+		HTTPExcludeMethods:   getEnvList("OTEL_HTTP_EXCLUDE_METHODS", nil),
+
+		HTTPMetricsUnmatchedRoute:      getEnv("OTEL_HTTP_METRICS_UNMATCHED_ROUTE", "unmatched"),
+		HTTPMetricsStatusClasses:       getEnvBool("OTEL_HTTP_METRICS_STATUS_CLASSES", false),
+		HTTPMetricsAttributeValueLimit: getEnvInt("OTEL_HTTP_METRICS_ATTRIBUTE_VALUE_LIMIT", 100),
+
+		BaggageSpanKeys:   getEnvList("OTEL_BAGGAGE_SPAN_KEYS", []string{"tenant.id", "client.app"}),
+		BaggageLogKeys:    getEnvList("OTEL_BAGGAGE_LOG_KEYS", []string{"tenant.id", "client.app"}),
+		BaggageMetricKeys: getEnvList("OTEL_BAGGAGE_METRIC_KEYS", []string{"client.app"}),
+
+		// Metrics readers
+		MetricsOTLPEnabled:       getEnvBool("OTEL_METRICS_OTLP_ENABLED", true),
+		MetricsPrometheusEnabled: getEnvBool("OTEL_METRICS_PROMETHEUS_ENABLED", false),
+		MetricsViewsFile:         getEnv("OTEL_METRICS_VIEWS_FILE", ""),
+
+		// Metrics export tuning
+		MetricsExportInterval:       getEnvDuration("OTEL_METRICS_EXPORT_INTERVAL", 15*time.Second),
+		MetricsExportTimeout:        getEnvDuration("OTEL_METRICS_EXPORT_TIMEOUT", 10*time.Second),
+		MetricsTemporality:          getEnv("OTEL_METRICS_TEMPORALITY", "cumulative"),
+		MetricsTemporalityOverrides: getEnvMap("OTEL_METRICS_TEMPORALITY_OVERRIDES"),
+		MetricsExemplarFilter:       getEnv("OTEL_METRICS_EXEMPLAR_FILTER", "trace_based"),
+		MetricsCardinalityLimit:     getEnvInt("OTEL_METRICS_CARDINALITY_LIMIT", 2000),
+
+		// Service level objectives
+		SLOObjectivesFile: getEnv("SLO_OBJECTIVES_FILE", ""),
+
 		// Server performance tuning
-		Prefork: getEnvBool("FIBER_PREFORK", false),
+		Prefork:    getEnvBool("FIBER_PREFORK", false),
+		AdminAddr:  getEnv("ADMIN_ADDR", ""),
+		AdminToken: getEnv("ADMIN_TOKEN", ""),
+
+		// Outbound HTTP client configuration
+		HTTPClientTimeout:            getEnvDuration("HTTP_CLIENT_TIMEOUT", 10*time.Second),
+		HTTPClientHostTimeouts:       getEnvDurationMap("HTTP_CLIENT_HOST_TIMEOUTS"),
+		HTTPClientMaxRetries:         getEnvInt("HTTP_CLIENT_MAX_RETRIES", 2),
+		HTTPClientRetryBackoff:       getEnvDuration("HTTP_CLIENT_RETRY_BACKOFF", 100*time.Millisecond),
+		HTTPClientRetryMaxBackoff:    getEnvDuration("HTTP_CLIENT_RETRY_MAX_BACKOFF", 2*time.Second),
+		HTTPClientBreakerThreshold:   getEnvInt("HTTP_CLIENT_BREAKER_THRESHOLD", 5),
+		HTTPClientBreakerOpenTimeout: getEnvDuration("HTTP_CLIENT_BREAKER_OPEN_TIMEOUT", 30*time.Second),
 
 		// Database configuration
 		DatabaseURL: func() string {
@@ -78,17 +188,52 @@ func NewConfig() *Config {
 			name := getEnv("DB_NAME", "appdb")
 			return "postgres://" + user + ":" + pass + "@" + host + ":" + port + "/" + name + "?sslmode=disable"
 		}(),
+		DatabaseReplicaURLs:           getEnvList("DATABASE_REPLICA_URLS", nil),
+		DatabaseReplicaHealthInterval: getEnvDuration("DATABASE_REPLICA_HEALTH_INTERVAL", 5*time.Second),
+		DBStatementTimeout:            getEnvDuration("DB_STATEMENT_TIMEOUT", 5*time.Second),
+		DBServerStatementTimeout:      getEnvDuration("DB_SERVER_STATEMENT_TIMEOUT", 0),
+		DBQueryExecMode:               getEnv("DB_QUERY_EXEC_MODE", ""),
+		DBSlowQueryThreshold:          getEnvDuration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+		DBSlowQueryExplainRate:        getEnvFloat("DB_SLOW_QUERY_EXPLAIN_RATE", 0),
 		RedisURL: func() string {
 			if url := os.Getenv("REDIS_URL"); url != "" {
 				return url
 			}
+			// The SQLite backend runs standalone unless Redis is configured
+			if os.Getenv("REDIS_HOST") == "" && isSQLiteURL(os.Getenv("DATABASE_URL")) {
+				return ""
+			}
 			host := getEnv("REDIS_HOST", "localhost")
 			port := getEnv("REDIS_PORT", "6379")
 			return "redis://" + host + ":" + port + "/0"
 		}(),
+
+		// Cache
+		UserCacheTTL:             getEnvDuration("USER_CACHE_TTL", 10*time.Minute),
+		UserCacheStaleTTL:        getEnvDuration("USER_CACHE_STALE_TTL", time.Minute),
+		CacheTTLJitter:           getEnvFloat("CACHE_TTL_JITTER", 0.1),
+		CacheCodec:               getEnv("CACHE_CODEC", "json"),
+		CacheInvalidationChannel: getEnv("CACHE_INVALIDATION_CHANNEL", "cache:invalidate"),
+		CacheRedeleteDelay:       getEnvDuration("CACHE_REDELETE_DELAY", 0),
+
+		// Dependency resilience
+		StartupRetryTimeout:          getEnvDuration("STARTUP_RETRY_TIMEOUT", 60*time.Second),
+		StartupRetryBackoff:          getEnvDuration("STARTUP_RETRY_BACKOFF", 500*time.Millisecond),
+		StartupRetryMaxBackoff:       getEnvDuration("STARTUP_RETRY_MAX_BACKOFF", 10*time.Second),
+		DependencyBreakerThreshold:   getEnvInt("DEPENDENCY_BREAKER_THRESHOLD", 5),
+		DependencyBreakerOpenTimeout: getEnvDuration("DEPENDENCY_BREAKER_OPEN_TIMEOUT", 10*time.Second),
 	}
 }
 
+// UsesSQLite reports whether DatabaseURL selects the SQLite backend.
+func (c *Config) UsesSQLite() bool {
+	return isSQLiteURL(c.DatabaseURL)
+}
+
+func isSQLiteURL(url string) bool {
+	return strings.HasPrefix(url, "sqlite:") || strings.HasPrefix(url, "file:")
+}
+
 // Helper functions for environment variable parsing
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -124,6 +269,49 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getEnvList parses a comma-separated list, dropping empty entries.
+// Setting the variable to "-" yields an empty list (disables the default).
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	if value == "-" {
+		return nil
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvDurationMap parses "key=duration" pairs separated by commas, skipping invalid entries.
+func getEnvDurationMap(key string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for k, v := range getEnvMap(key) {
+		if parsed, err := time.ParseDuration(v); err == nil {
+			result[k] = parsed
+		}
+	}
+	return result
+}
+
+func getEnvMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range getEnvList(key, nil) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
+
 func getEnvFloat(key string, defaultValue float64) float64 {
 	if value := os.Getenv(key); value != "" {
 		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
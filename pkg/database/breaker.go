@@ -0,0 +1,357 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"gofiberobservability/pkg/breaker"
+	"gofiberobservability/pkg/config"
+	"gofiberobservability/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	breakersMu         sync.Mutex
+	breakers           []*breaker.Breaker
+	breakerMetricsOnce sync.Once
+	breakerMetricsErr  error
+)
+
+// newBreaker creates a circuit breaker for a dependency and exports its state.
+func newBreaker(name string, cfg *config.Config) (*breaker.Breaker, error) {
+	breakerMetricsOnce.Do(func() {
+		breakerMetricsErr = registerBreakerMetrics()
+	})
+	if breakerMetricsErr != nil {
+		return nil, fmt.Errorf("failed to register circuit breaker metrics: %w", breakerMetricsErr)
+	}
+
+	br := breaker.New(name, cfg.DependencyBreakerThreshold, cfg.DependencyBreakerOpenTimeout)
+
+	breakersMu.Lock()
+	breakers = append(breakers, br)
+	breakersMu.Unlock()
+
+	return br, nil
+}
+
+// BreakerStates returns the state of every dependency circuit breaker by name.
+func BreakerStates() map[string]string {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	states := make(map[string]string, len(breakers))
+	for _, br := range breakers {
+		states[br.Name()] = br.State().String()
+	}
+	return states
+}
+
+// registerBreakerMetrics observes breaker states as 0 (closed), 1 (half-open) or 2 (open).
+func registerBreakerMetrics() error {
+	meter := metrics.GetMeter()
+
+	state, err := meter.Int64ObservableGauge("db.client.circuit_breaker.state",
+		metric.WithDescription("Dependency circuit breaker state: 0 closed, 1 half-open, 2 open"),
+		metric.WithUnit("{state}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		breakersMu.Lock()
+		defer breakersMu.Unlock()
+
+		for _, br := range breakers {
+			o.ObserveInt64(state, int64(br.State()), metric.WithAttributes(attribute.String("breaker.name", br.Name())))
+		}
+		return nil
+	}, state)
+	return err
+}
+
+// GuardedPool runs queries through a circuit breaker: while the breaker is
+// open, calls fail fast with breaker.ErrOpen instead of waiting on a dead
+// server. Only connection-level failures count against it; SQL errors such
+// as a unique violation mean the server is answering, and statement timeouts
+// or cancellations by the caller are about the query, not the server.
+//
+// Queries also get the default statement timeout as a context deadline,
+// covering the pool acquire, so a stuck query can't hold a connection
+// forever. Transactions are not bounded; only a server-side
+// statement_timeout caps their statements.
+type GuardedPool struct {
+	pool    *pgxpool.Pool
+	br      *breaker.Breaker
+	timeout time.Duration
+}
+
+func newGuardedPool(p *pgxpool.Pool, br *breaker.Breaker, timeout time.Duration) *GuardedPool {
+	return &GuardedPool{pool: p, br: br, timeout: timeout}
+}
+
+// Pool returns the underlying pool, bypassing the breaker and timeout.
+func (g *GuardedPool) Pool() *pgxpool.Pool {
+	return g.pool
+}
+
+func (g *GuardedPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if err := g.allow(); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	ctx, cancel := statementContext(ctx, g.timeout)
+	defer cancel()
+
+	tag, err := g.pool.Exec(ctx, sql, args...)
+	g.record(err)
+	return tag, err
+}
+
+// Query keeps the statement deadline until the rows are closed.
+func (g *GuardedPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if err := g.allow(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := statementContext(ctx, g.timeout)
+
+	rows, err := g.pool.Query(ctx, sql, args...)
+	g.record(err)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &timeoutRows{Rows: rows, cancel: cancel}, nil
+}
+
+// QueryRow defers the breaker outcome and the deadline to Scan, where pgx
+// reports the error.
+func (g *GuardedPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if err := g.allow(); err != nil {
+		return errRow{err: err}
+	}
+	ctx, cancel := statementContext(ctx, g.timeout)
+	return guardedRow{row: g.pool.QueryRow(ctx, sql, args...), g: g, cancel: cancel}
+}
+
+func (g *GuardedPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	if err := g.allow(); err != nil {
+		return nil, err
+	}
+	tx, err := g.pool.Begin(ctx)
+	g.record(err)
+	return tx, err
+}
+
+func (g *GuardedPool) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	if err := g.allow(); err != nil {
+		return nil, err
+	}
+	tx, err := g.pool.BeginTx(ctx, opts)
+	g.record(err)
+	return tx, err
+}
+
+func (g *GuardedPool) Ping(ctx context.Context) error {
+	if err := g.allow(); err != nil {
+		return err
+	}
+	err := g.pool.Ping(ctx)
+	g.record(err)
+	return err
+}
+
+func (g *GuardedPool) allow() error {
+	if err := g.br.Allow(); err != nil {
+		return fmt.Errorf("%s: %w", g.br.Name(), err)
+	}
+	return nil
+}
+
+func (g *GuardedPool) record(err error) {
+	switch {
+	case isPostgresFailure(err):
+		g.br.Failure()
+	case isCallerAbort(err):
+		g.br.Ignore()
+	default:
+		g.br.Success()
+	}
+}
+
+type guardedRow struct {
+	row    pgx.Row
+	g      *GuardedPool
+	cancel context.CancelFunc
+}
+
+func (r guardedRow) Scan(dest ...any) error {
+	defer r.cancel()
+	err := r.row.Scan(dest...)
+	r.g.record(err)
+	return err
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+// timeoutRows releases the statement deadline once the rows are done.
+type timeoutRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.cancel()
+	return false
+}
+
+func (r *timeoutRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+// isPostgresFailure reports whether err means the server is unreachable or
+// unable to serve, as opposed to rejecting this particular query. Failing to
+// connect counts even when the caller's deadline cut it short (a server
+// that doesn't answer looks like that); a deadline or cancellation during a
+// query doesn't.
+func isPostgresFailure(err error) bool {
+	if err == nil || errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) && !errors.Is(err, context.Canceled) {
+		return true
+	}
+	if isCallerAbort(err) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+			return true
+		}
+		class, _ := classifyError(err)
+		return class == "08" || class == "53"
+	}
+	_, errType := classifyError(err)
+	return errType == "connection"
+}
+
+// isCallerAbort reports whether the query ended because of its own deadline
+// or cancellation: the statement timeout, the request going away, or
+// Postgres canceling it (57014 query_canceled, also raised by the
+// server-side statement_timeout).
+func isCallerAbort(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "57014"
+}
+
+// breakerHook puts the Redis client behind a circuit breaker. It is added
+// after the otel hooks, so rejected commands still show up in traces.
+// Commands on a bypassBreaker ctx skip it entirely.
+type breakerHook struct {
+	br *breaker.Breaker
+}
+
+type bypassBreakerKey struct{}
+
+// bypassBreaker marks ctx for health probes: they must reach Redis while the
+// breaker is open, and their outcome must not move it.
+func bypassBreaker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassBreakerKey{}, true)
+}
+
+func breakerBypassed(ctx context.Context) bool {
+	return ctx.Value(bypassBreakerKey{}) != nil
+}
+
+func (h breakerHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h breakerHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if breakerBypassed(ctx) {
+			return next(ctx, cmd)
+		}
+		if err := h.br.Allow(); err != nil {
+			err = fmt.Errorf("%s: %w", h.br.Name(), err)
+			cmd.SetErr(err)
+			return err
+		}
+		err := next(ctx, cmd)
+		h.record(err)
+		return err
+	}
+}
+
+func (h breakerHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if breakerBypassed(ctx) {
+			return next(ctx, cmds)
+		}
+		if err := h.br.Allow(); err != nil {
+			err = fmt.Errorf("%s: %w", h.br.Name(), err)
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		err := next(ctx, cmds)
+		h.record(err)
+		return err
+	}
+}
+
+// record ignores commands canceled or timed out by their caller: they say
+// nothing about Redis, and must not close a half-open breaker.
+func (h breakerHook) record(err error) {
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		h.br.Ignore()
+	case isRedisFailure(err):
+		h.br.Failure()
+	default:
+		h.br.Success()
+	}
+}
+
+// isRedisFailure reports whether err is a transport failure rather than a
+// reply from the server (redis.Nil, WRONGTYPE, ...).
+func isRedisFailure(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var replyErr redis.Error
+	return !errors.As(err, &replyErr)
+}
@@ -0,0 +1,255 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// recoverySQL reports whether the node is a standby and how far its replay
+// lags. Lag is 0 when everything received has been replayed, so an idle
+// primary doesn't make its replicas look stale.
+const recoverySQL = `SELECT pg_is_in_recovery(),
+	CASE
+		WHEN NOT pg_is_in_recovery() THEN NULL
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())::float8
+	END`
+
+// StatusDisabled is the status of a dependency the service runs without.
+const StatusDisabled = "disabled"
+
+// CheckResult is the outcome of pinging a dependency.
+type CheckResult struct {
+	Status    string  `json:"status"` // up, down or disabled
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Up reports whether the dependency answered.
+func (r CheckResult) Up() bool {
+	return r.Status == "up"
+}
+
+// Healthy reports whether the dependency answered or is not used.
+func (r CheckResult) Healthy() bool {
+	return r.Up() || r.Status == StatusDisabled
+}
+
+// PostgresHealth is the health of one Postgres node. Fields other than the
+// check result and node are only filled in by verbose checks.
+type PostgresHealth struct {
+	CheckResult
+	Node                  string     `json:"node"`
+	CircuitBreaker        string     `json:"circuit_breaker,omitempty"`
+	InRecovery            *bool      `json:"in_recovery,omitempty"`
+	ReplicationLagSeconds *float64   `json:"replication_lag_seconds,omitempty"`
+	Pool                  *PoolStats `json:"pool,omitempty"`
+}
+
+// PoolStats is a snapshot of pgxpool.Stat.
+type PoolStats struct {
+	TotalConns        int32   `json:"total_conns"`
+	IdleConns         int32   `json:"idle_conns"`
+	AcquiredConns     int32   `json:"acquired_conns"`
+	ConstructingConns int32   `json:"constructing_conns"`
+	MaxConns          int32   `json:"max_conns"`
+	AcquireCount      int64   `json:"acquire_count"`
+	EmptyAcquireCount int64   `json:"empty_acquire_count"`
+	CanceledAcquires  int64   `json:"canceled_acquire_count"`
+	AvgAcquireMS      float64 `json:"avg_acquire_ms"`
+}
+
+// SQLiteHealth is the health of the SQLite database. Fields other than the
+// check result are only filled in by verbose checks.
+type SQLiteHealth struct {
+	CheckResult
+	Path        string `json:"path,omitempty"`
+	JournalMode string `json:"journal_mode,omitempty"`
+}
+
+// RedisHealth is the health of the Redis server. Fields other than the check
+// result are only filled in by verbose checks.
+type RedisHealth struct {
+	CheckResult
+	CircuitBreaker  string          `json:"circuit_breaker,omitempty"`
+	Role            string          `json:"role,omitempty"`
+	UsedMemoryBytes int64           `json:"used_memory_bytes,omitempty"`
+	MaxMemoryBytes  int64           `json:"maxmemory_bytes,omitempty"`
+	Pool            *RedisPoolStats `json:"pool,omitempty"`
+}
+
+// RedisPoolStats is a snapshot of the go-redis pool counters.
+type RedisPoolStats struct {
+	Hits       uint32 `json:"hits"`
+	Misses     uint32 `json:"misses"`
+	Timeouts   uint32 `json:"timeouts"`
+	TotalConns uint32 `json:"total_conns"`
+	IdleConns  uint32 `json:"idle_conns"`
+	StaleConns uint32 `json:"stale_conns"`
+}
+
+// HealthCheck pings the primary. Verbose checks add pool statistics, the
+// breaker state and recovery/replication lag.
+func HealthCheck(ctx context.Context, verbose bool) PostgresHealth {
+	if pool == nil {
+		return PostgresHealth{Node: primaryPoolName, CheckResult: down(0, errors.New("database not initialized"))}
+	}
+	return checkPostgres(ctx, primaryPoolName, primary, verbose)
+}
+
+// ReplicaHealthCheck checks every read replica like HealthCheck.
+func ReplicaHealthCheck(ctx context.Context, verbose bool) []PostgresHealth {
+	results := make([]PostgresHealth, 0, len(replicas))
+	for _, r := range replicas {
+		results = append(results, checkPostgres(ctx, r.name, r.db, verbose))
+	}
+	return results
+}
+
+// checkPostgres pings the pool directly: the breaker may be open, and the
+// point of a health check is to see whether the node is back.
+func checkPostgres(ctx context.Context, node string, db *GuardedPool, verbose bool) PostgresHealth {
+	ctx = WithOperation(ctx, "health.ping")
+
+	start := time.Now()
+	err := db.pool.Ping(ctx)
+	h := PostgresHealth{Node: node, CheckResult: result(start, err)}
+	if !verbose {
+		return h
+	}
+
+	h.CircuitBreaker = db.br.State().String()
+	h.Pool = poolStats(db.pool.Stat())
+	if err != nil {
+		return h
+	}
+
+	var inRecovery bool
+	var lag *float64
+	if err := db.pool.QueryRow(WithOperation(ctx, "health.recovery"), recoverySQL).Scan(&inRecovery, &lag); err != nil {
+		h.Error = "recovery status: " + err.Error()
+		return h
+	}
+	h.InRecovery = &inRecovery
+	h.ReplicationLagSeconds = lag
+	return h
+}
+
+// SQLiteHealthCheck pings the SQLite database. Verbose checks add its path
+// and journal mode.
+func SQLiteHealthCheck(ctx context.Context, verbose bool) SQLiteHealth {
+	if sqliteDB == nil {
+		return SQLiteHealth{CheckResult: down(0, errors.New("database not initialized"))}
+	}
+
+	start := time.Now()
+	err := sqliteDB.db.PingContext(ctx)
+	h := SQLiteHealth{CheckResult: result(start, err)}
+	if !verbose || err != nil {
+		return h
+	}
+
+	h.Path = sqliteDB.dsn
+	if err := sqliteDB.QueryRow(WithOperation(ctx, "health.journal_mode"), "PRAGMA journal_mode").Scan(&h.JournalMode); err != nil {
+		h.Error = "journal mode: " + err.Error()
+	}
+	return h
+}
+
+// RedisHealthCheck pings Redis. Verbose checks add the replication role,
+// memory usage, pool statistics and the breaker state. Without a Redis URL
+// (SQLite mode) the status is "disabled".
+func RedisHealthCheck(ctx context.Context, verbose bool) RedisHealth {
+	if redisDisabled {
+		return RedisHealth{CheckResult: CheckResult{Status: StatusDisabled}}
+	}
+	if rdb == nil {
+		return RedisHealth{CheckResult: down(0, errors.New("redis client not initialized"))}
+	}
+
+	// Like the Postgres checks, bypass the breaker: report Redis itself
+	ctx = bypassBreaker(ctx)
+	start := time.Now()
+	err := rdb.Ping(ctx).Err()
+	h := RedisHealth{CheckResult: result(start, err)}
+	if !verbose {
+		return h
+	}
+
+	if redisBreaker != nil {
+		h.CircuitBreaker = redisBreaker.State().String()
+	}
+	s := rdb.PoolStats()
+	h.Pool = &RedisPoolStats{
+		Hits:       s.Hits,
+		Misses:     s.Misses,
+		Timeouts:   s.Timeouts,
+		TotalConns: s.TotalConns,
+		IdleConns:  s.IdleConns,
+		StaleConns: s.StaleConns,
+	}
+	if err != nil {
+		return h
+	}
+
+	info, err := rdb.Info(ctx, "replication", "memory").Result()
+	if err != nil {
+		h.Error = "info: " + err.Error()
+		return h
+	}
+	fields := parseRedisInfo(info)
+	h.Role = fields["role"]
+	h.UsedMemoryBytes, _ = strconv.ParseInt(fields["used_memory"], 10, 64)
+	h.MaxMemoryBytes, _ = strconv.ParseInt(fields["maxmemory"], 10, 64)
+	return h
+}
+
+// parseRedisInfo turns INFO output ("key:value" lines, "# Section" headers)
+// into a map.
+func parseRedisInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	for line := range strings.Lines(info) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+func poolStats(s *pgxpool.Stat) *PoolStats {
+	ps := &PoolStats{
+		TotalConns:        s.TotalConns(),
+		IdleConns:         s.IdleConns(),
+		AcquiredConns:     s.AcquiredConns(),
+		ConstructingConns: s.ConstructingConns(),
+		MaxConns:          s.MaxConns(),
+		AcquireCount:      s.AcquireCount(),
+		EmptyAcquireCount: s.EmptyAcquireCount(),
+		CanceledAcquires:  s.CanceledAcquireCount(),
+	}
+	if n := s.AcquireCount(); n > 0 {
+		ps.AvgAcquireMS = float64(s.AcquireDuration().Microseconds()) / 1000 / float64(n)
+	}
+	return ps
+}
+
+func result(start time.Time, err error) CheckResult {
+	latency := float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		return down(latency, err)
+	}
+	return CheckResult{Status: "up", LatencyMS: latency}
+}
+
+func down(latency float64, err error) CheckResult {
+	return CheckResult{Status: "down", LatencyMS: latency, Error: err.Error()}
+}
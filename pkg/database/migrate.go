@@ -0,0 +1,488 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+//go:embed migrations/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey is the pg_advisory_lock key every instance takes before
+// touching the schema, so pods starting together migrate one at a time.
+const migrationLockKey int64 = 0x676f6669626572 // "gofiber"
+
+const createMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version      BIGINT PRIMARY KEY,
+	name         TEXT NOT NULL,
+	checksum     TEXT NOT NULL,
+	applied_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	execution_ms BIGINT NOT NULL DEFAULT 0
+)`
+
+// Migration is one numbered schema change, loaded from
+// migrations/<version>_<name>.up.sql and the matching .down.sql.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 of Up; an applied migration must not change
+}
+
+// MigrationStatus is the state of one migration in the database.
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Modified reports an applied migration whose file changed since.
+	Modified bool `json:"modified,omitempty"`
+	// Unknown reports an applied version with no embedded file (e.g. after a rollback of the binary).
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// MigrateOptions controls Up and Down.
+type MigrateOptions struct {
+	// Target is the version to migrate to. Up applies every pending version
+	// <= Target (0 = latest); Down reverts every applied version > Target.
+	Target int64
+	// DryRun logs the plan and SQL without executing anything.
+	DryRun bool
+}
+
+// Migrator applies the embedded migrations to a pool.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	log        *zap.Logger
+	migrations []Migration
+	tracer     trace.Tracer
+}
+
+// NewMigrator loads the embedded migrations for p.
+func NewMigrator(p *pgxpool.Pool, log *zap.Logger) (*Migrator, error) {
+	migrations, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		pool:       p,
+		log:        log,
+		migrations: migrations,
+		tracer:     otel.Tracer("gofiberobservability/database"),
+	}, nil
+}
+
+// LoadMigrations reads <version>_<name>.(up|down).sql files from dir in fsys,
+// sorted by version. Every version needs an up file; down files are optional
+// but required to roll that version back. Subdirectories hold per-database
+// overrides (see LoadDialectMigrations) and are skipped.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		file := e.Name()
+		version, name, direction, err := parseMigrationFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d: up and down files have different names (%s, %s)", version, m.Name, name)
+		}
+		if direction == "up" {
+			sum := sha256.Sum256(data)
+			m.Up, m.Checksum = string(data), hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+// LoadDialectMigrations loads the shared migrations in dir and replaces the
+// up or down SQL of those with a file of the same name in dir/dialect, for
+// statements the other database doesn't understand. Versions without an
+// override run the shared SQL.
+func LoadDialectMigrations(fsys fs.FS, dir, dialect string) ([]Migration, error) {
+	migrations, err := LoadMigrations(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	overrideDir := path.Join(dir, dialect)
+	entries, err := fs.ReadDir(fsys, overrideDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return migrations, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s migrations: %w", dialect, err)
+	}
+
+	for _, e := range entries {
+		file := e.Name()
+		version, name, direction, err := parseMigrationFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", dialect, err)
+		}
+		i := slices.IndexFunc(migrations, func(m Migration) bool { return m.Version == version })
+		if i < 0 || migrations[i].Name != name {
+			return nil, fmt.Errorf("%s migration %s overrides no shared migration", dialect, file)
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(overrideDir, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+		if direction == "up" {
+			sum := sha256.Sum256(data)
+			migrations[i].Up, migrations[i].Checksum = string(data), hex.EncodeToString(sum[:])
+		} else {
+			migrations[i].Down = string(data)
+		}
+	}
+	return migrations, nil
+}
+
+// parseMigrationFile splits <version>_<name>.(up|down).sql.
+func parseMigrationFile(file string) (version int64, name, direction string, err error) {
+	base, direction, ok := strings.Cut(strings.TrimSuffix(file, ".sql"), ".")
+	if !strings.HasSuffix(file, ".sql") || !ok || (direction != "up" && direction != "down") {
+		return 0, "", "", fmt.Errorf("migration %s: want <version>_<name>.up.sql or .down.sql", file)
+	}
+	num, name, _ := strings.Cut(base, "_")
+	version, err = strconv.ParseInt(num, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("migration %s: version must be a positive number", file)
+	}
+	return version, name, direction, nil
+}
+
+// RunMigrations applies every pending migration to the primary pool, or to
+// the SQLite database in SQLite mode.
+func RunMigrations(ctx context.Context, log *zap.Logger) error {
+	if sqliteDB != nil {
+		return migrateSQLite(ctx, sqliteDB, log)
+	}
+	m, err := NewMigrator(pool, log)
+	if err != nil {
+		return err
+	}
+	return m.Up(ctx, MigrateOptions{})
+}
+
+// Up applies pending migrations up to opts.Target.
+func (m *Migrator) Up(ctx context.Context, opts MigrateOptions) error {
+	return m.withLock(ctx, opts.DryRun, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		var pending []Migration
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; !ok && (opts.Target == 0 || mig.Version <= opts.Target) {
+				pending = append(pending, mig)
+			}
+		}
+		if len(pending) == 0 {
+			m.log.Info("Database schema is up to date", zap.Int64("version", maxVersion(applied)))
+			return nil
+		}
+
+		for _, mig := range pending {
+			if opts.DryRun {
+				m.log.Info("Dry run: would apply migration",
+					zap.Int64("version", mig.Version), zap.String("name", mig.Name), zap.String("sql", mig.Up))
+				continue
+			}
+			if err := m.run(ctx, conn, mig, "up"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts applied migrations newer than opts.Target, newest first.
+func (m *Migrator) Down(ctx context.Context, opts MigrateOptions) error {
+	return m.withLock(ctx, opts.DryRun, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for _, mig := range slices.Backward(m.migrations) {
+			if _, ok := applied[mig.Version]; !ok || mig.Version <= opts.Target {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+			}
+			if opts.DryRun {
+				m.log.Info("Dry run: would revert migration",
+					zap.Int64("version", mig.Version), zap.String("name", mig.Name), zap.String("sql", mig.Down))
+				continue
+			}
+			if err := m.run(ctx, conn, mig, "down"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every embedded migration with its applied state, plus applied
+// versions this binary does not know about.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if row, ok := applied[mig.Version]; ok {
+			s.Applied, s.AppliedAt = true, &row.appliedAt
+			s.Modified = row.checksum != mig.Checksum
+			delete(applied, mig.Version)
+		}
+		statuses = append(statuses, s)
+	}
+	for version, row := range applied {
+		statuses = append(statuses, MigrationStatus{
+			Version: version, Name: row.name, Applied: true, AppliedAt: &row.appliedAt, Unknown: true,
+		})
+	}
+	slices.SortFunc(statuses, func(a, b MigrationStatus) int { return cmp.Compare(a.Version, b.Version) })
+	return statuses, nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock. Dry runs only read, so they skip the lock and the table creation.
+func (m *Migrator) withLock(ctx context.Context, dryRun bool, fn func(*pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if dryRun {
+		return fn(conn)
+	}
+
+	ctx = WithOperation(ctx, "schema.migrate")
+
+	// Waiting for the lock and running migrations may take longer than the
+	// server-side statement_timeout applied to every connection
+	if _, err := conn.Exec(ctx, "SET statement_timeout = 0"); err != nil {
+		return fmt.Errorf("failed to disable statement timeout: %w", err)
+	}
+	defer func() {
+		resetCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(resetCtx, "RESET statement_timeout"); err != nil {
+			// Don't hand a connection without a timeout back to the pool
+			conn.Conn().Close(resetCtx)
+		}
+	}()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", migrationLockKey).Scan(&locked); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	if !locked {
+		m.log.Info("Waiting for another instance to finish migrating")
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+			return fmt.Errorf("failed to take migration lock: %w", err)
+		}
+	}
+	defer func() {
+		// The request context may be done; the lock must still be released
+		unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			m.log.Warn("Failed to release migration lock", zap.Error(err))
+		}
+	}()
+
+	if _, err := conn.Exec(ctx, createMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+// run applies or reverts one migration and records it, in a single transaction.
+func (m *Migrator) run(ctx context.Context, conn *pgxpool.Conn, mig Migration, direction string) (err error) {
+	ctx, span := m.tracer.Start(ctx, "db.migrate "+direction, trace.WithAttributes(
+		attribute.Int64("db.migration.version", mig.Version),
+		attribute.String("db.migration.name", mig.Name),
+		attribute.String("db.migration.direction", direction),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	ctx = WithOperation(ctx, "schema.migrate")
+	start := time.Now()
+
+	err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if direction == "up" {
+			if _, err := tx.Exec(ctx, mig.Up); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx,
+				"INSERT INTO schema_migrations (version, name, checksum, execution_ms) VALUES ($1, $2, $3, $4)",
+				mig.Version, mig.Name, mig.Checksum, time.Since(start).Milliseconds())
+			return err
+		}
+		if _, err := tx.Exec(ctx, mig.Down); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", mig.Version)
+		return err
+	})
+
+	log := m.log.With(
+		zap.Int64("version", mig.Version),
+		zap.String("name", mig.Name),
+		zap.String("direction", direction),
+		zap.Duration("duration", time.Since(start)),
+	)
+	if err != nil {
+		log.Error("Migration failed, rolled back", zap.Error(err))
+		return fmt.Errorf("migration %d_%s %s: %w", mig.Version, mig.Name, direction, err)
+	}
+	log.Info("Migration completed")
+	return nil
+}
+
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// applied reads schema_migrations; a missing table means nothing is applied.
+func (m *Migrator) applied(ctx context.Context, conn *pgxpool.Conn) (map[int64]appliedMigration, error) {
+	ctx = WithOperation(ctx, "schema.status")
+
+	var exists bool
+	if err := conn.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	applied := make(map[int64]appliedMigration)
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := conn.Query(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int64
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+// verify refuses to migrate when an applied migration's file was edited.
+func (m *Migrator) verify(applied map[int64]appliedMigration) error {
+	return verifyChecksums(m.migrations, applied)
+}
+
+func verifyChecksums(migrations []Migration, applied map[int64]appliedMigration) error {
+	var errs []error
+	for _, mig := range migrations {
+		if a, ok := applied[mig.Version]; ok && a.checksum != mig.Checksum {
+			errs = append(errs, fmt.Errorf("migration %d_%s was modified after being applied (checksum %s, file %s)",
+				mig.Version, mig.Name, shortChecksum(a.checksum), shortChecksum(mig.Checksum)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// shortChecksum abbreviates a checksum for messages; stored ones may be
+// empty or truncated.
+func shortChecksum(sum string) string {
+	if sum == "" {
+		return "(none)"
+	}
+	return sum[:min(len(sum), 12)]
+}
+
+func maxVersion(applied map[int64]appliedMigration) int64 {
+	var v int64
+	for version := range applied {
+		v = max(v, version)
+	}
+	return v
+}
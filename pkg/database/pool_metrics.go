@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+
+	"gofiberobservability/pkg/metrics"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const poolNameKey = attribute.Key("db.client.connection.pool.name")
+
+// registerPoolMetrics exports pgxpool.Stat as observable instruments labeled by pool name.
+// Stat values are cumulative, so counters are observed as-is on each collection.
+func registerPoolMetrics(p *pgxpool.Pool, poolName string) (metric.Registration, error) {
+	meter := metrics.GetMeter()
+
+	connCount, err := meter.Int64ObservableUpDownCounter("db.client.connection.count",
+		metric.WithDescription("Connections in the pool by state (idle, used, constructing)"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	connMax, err := meter.Int64ObservableUpDownCounter("db.client.connection.max",
+		metric.WithDescription("Maximum number of connections allowed in the pool"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	acquires, err := meter.Int64ObservableCounter("db.client.connection.acquires",
+		metric.WithDescription("Successful connection acquires from the pool"),
+		metric.WithUnit("{acquire}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	acquireDuration, err := meter.Float64ObservableCounter("db.client.connection.acquire.duration",
+		metric.WithDescription("Total time spent acquiring connections"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	emptyAcquires, err := meter.Int64ObservableCounter("db.client.connection.acquires.empty",
+		metric.WithDescription("Acquires that had to wait because the pool had no idle connection"),
+		metric.WithUnit("{acquire}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	emptyAcquireWait, err := meter.Float64ObservableCounter("db.client.connection.acquire.empty_wait",
+		metric.WithDescription("Total time spent waiting in acquires that found the pool empty"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	canceledAcquires, err := meter.Int64ObservableCounter("db.client.connection.acquires.canceled",
+		metric.WithDescription("Acquires canceled by their context before a connection was available"),
+		metric.WithUnit("{acquire}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	created, err := meter.Int64ObservableCounter("db.client.connection.created",
+		metric.WithDescription("New connections opened by the pool"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	destroyed, err := meter.Int64ObservableCounter("db.client.connection.destroyed",
+		metric.WithDescription("Connections closed by the pool, by reason (max_lifetime, max_idle)"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	poolAttr := poolNameKey.String(poolName)
+	withPool := metric.WithAttributes(poolAttr)
+	stateAttrs := func(state string) metric.ObserveOption {
+		return metric.WithAttributes(poolAttr, attribute.String("db.client.connection.state", state))
+	}
+	reasonAttrs := func(reason string) metric.ObserveOption {
+		return metric.WithAttributes(poolAttr, attribute.String("reason", reason))
+	}
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := p.Stat()
+
+		o.ObserveInt64(connCount, int64(s.IdleConns()), stateAttrs("idle"))
+		o.ObserveInt64(connCount, int64(s.AcquiredConns()), stateAttrs("used"))
+		o.ObserveInt64(connCount, int64(s.ConstructingConns()), stateAttrs("constructing"))
+		o.ObserveInt64(connMax, int64(s.MaxConns()), withPool)
+
+		o.ObserveInt64(acquires, s.AcquireCount(), withPool)
+		o.ObserveFloat64(acquireDuration, s.AcquireDuration().Seconds(), withPool)
+		o.ObserveInt64(emptyAcquires, s.EmptyAcquireCount(), withPool)
+		o.ObserveFloat64(emptyAcquireWait, s.EmptyAcquireWaitTime().Seconds(), withPool)
+		o.ObserveInt64(canceledAcquires, s.CanceledAcquireCount(), withPool)
+
+		o.ObserveInt64(created, s.NewConnsCount(), withPool)
+		o.ObserveInt64(destroyed, s.MaxLifetimeDestroyCount(), reasonAttrs("max_lifetime"))
+		o.ObserveInt64(destroyed, s.MaxIdleDestroyCount(), reasonAttrs("max_idle"))
+
+		return nil
+	}, connCount, connMax, acquires, acquireDuration, emptyAcquires, emptyAcquireWait, canceledAcquires, created, destroyed)
+}
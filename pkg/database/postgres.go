@@ -8,39 +8,54 @@ import (
 	"gofiberobservability/pkg/config"
 
 	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.uber.org/zap"
 )
 
-var pool *pgxpool.Pool
+// primaryPoolName labels metrics of the main read/write pool.
+const primaryPoolName = "primary"
+
+var (
+	pool        *pgxpool.Pool
+	primary     *GuardedPool
+	poolMetrics metric.Registration
+)
 
 // InitDatabase initializes the PostgreSQL connection pool with OTEL tracing instrumentation.
+// When read replicas are configured, a pool is opened for each of them too.
+// A sqlite:// or file: DATABASE_URL opens a SQLite database instead.
 func InitDatabase(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
-	pgxCfg, err := pgxpool.ParseConfig(cfg.DatabaseURL)
-	if err != nil {
-		return fmt.Errorf("failed to parse database URL: %w", err)
+	if cfg.UsesSQLite() {
+		return initSQLite(ctx, cfg, log)
 	}
 
-	// Production-grade pool settings
-	pgxCfg.MaxConns = 25
-	pgxCfg.MinConns = 5
-	pgxCfg.MaxConnLifetime = 1 * time.Hour
-	pgxCfg.MaxConnIdleTime = 30 * time.Minute
-	pgxCfg.HealthCheckPeriod = 1 * time.Minute
+	p, pgxCfg, err := newPool(ctx, cfg, cfg.DatabaseURL, primaryPoolName)
+	if err != nil {
+		return err
+	}
+	pool = p
 
-	// OpenTelemetry instrumentation: auto-trace every SQL query
-	pgxCfg.ConnConfig.Tracer = otelpgx.NewTracer(
-		otelpgx.WithIncludeQueryParameters(),
-	)
+	// Verify connectivity, waiting for a database that is still starting up
+	if err := retryStartup(ctx, cfg, log, "PostgreSQL", pool.Ping); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
 
-	pool, err = pgxpool.NewWithConfig(ctx, pgxCfg)
+	// Fail fast at runtime while the database is down
+	br, err := newBreaker("postgres."+primaryPoolName, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create connection pool: %w", err)
+		return err
 	}
+	primary = newGuardedPool(pool, br, cfg.DBStatementTimeout)
 
-	// Verify connectivity
-	if err := pool.Ping(ctx); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+	// Export pool statistics (acquires, waits, idle vs used connections)
+	poolMetrics, err = registerPoolMetrics(pool, primaryPoolName)
+	if err != nil {
+		return fmt.Errorf("failed to register pool metrics: %w", err)
 	}
 
 	log.Info("PostgreSQL connection pool initialized",
@@ -49,44 +64,99 @@ func InitDatabase(ctx context.Context, cfg *config.Config, log *zap.Logger) erro
 		zap.String("database", pgxCfg.ConnConfig.Database),
 		zap.Int32("max_conns", pgxCfg.MaxConns),
 		zap.Int32("min_conns", pgxCfg.MinConns),
+		zap.Stringer("query_exec_mode", pgxCfg.ConnConfig.DefaultQueryExecMode),
+		zap.Duration("statement_timeout", cfg.DBStatementTimeout),
 	)
 
+	if len(cfg.DatabaseReplicaURLs) > 0 {
+		if err := initReplicas(ctx, cfg, log); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// newPool creates an instrumented pool for dsn. poolName labels its spans and
+// metrics so the node that served a query is visible.
+func newPool(ctx context.Context, cfg *config.Config, dsn, poolName string) (*pgxpool.Pool, *pgxpool.Config, error) {
+	pgxCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+
+	// Production-grade pool settings
+	pgxCfg.MaxConns = 25
+	pgxCfg.MinConns = 5
+	pgxCfg.MaxConnLifetime = 1 * time.Hour
+	pgxCfg.MaxConnIdleTime = 30 * time.Minute
+	pgxCfg.HealthCheckPeriod = 1 * time.Minute
+
+	// Exec mode (PgBouncer compatibility) and server-side statement_timeout
+	if err := applyStatementConfig(pgxCfg, cfg); err != nil {
+		return nil, nil, err
+	}
+
+	// Per-operation latency and SQLSTATE-classified errors
+	queryMetrics, err := newQueryMetricsTracer(semconv.DBSystemPostgreSQL, poolName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create query metrics: %w", err)
+	}
+
+	// OpenTelemetry instrumentation: auto-trace every SQL query. otelpgx's own
+	// metrics are disabled: they only know the pgx call type and record
+	// milliseconds under the seconds unit of db.client.operation.duration.
+	tracers := []pgx.QueryTracer{
+		otelpgx.NewTracer(
+			otelpgx.WithIncludeQueryParameters(),
+			// Bounded span names: "query users.get", the SQL stays in db.query.text
+			otelpgx.WithTrimSQLInSpanName(),
+			otelpgx.WithSpanNameCtxFunc(operationName),
+			otelpgx.WithTracerAttributes(poolNameKey.String(poolName)),
+			otelpgx.WithMeterProvider(noop.NewMeterProvider()),
+		),
+		queryMetrics,
+	}
+
+	// Slow-query log goes first so the otelpgx span is still open when it ends
+	var slowQueries *slowQueryTracer
+	if cfg.DBSlowQueryThreshold > 0 {
+		slowQueries = newSlowQueryTracer(cfg.DBSlowQueryThreshold, cfg.DBSlowQueryExplainRate, poolName)
+		tracers = append([]pgx.QueryTracer{slowQueries}, tracers...)
+	}
+	pgxCfg.ConnConfig.Tracer = multitracer.New(tracers...)
+
+	p, err := pgxpool.NewWithConfig(ctx, pgxCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+	if slowQueries != nil {
+		slowQueries.pool = p
+	}
+	return p, pgxCfg, nil
+}
+
 // GetPool returns the database connection pool.
 func GetPool() *pgxpool.Pool {
 	return pool
 }
 
-// Close gracefully closes the connection pool.
+// Primary returns the primary pool behind its circuit breaker. Application
+// queries should use it (or Reader); GetPool is for tooling such as migrations.
+func Primary() *GuardedPool {
+	return primary
+}
+
+// Close gracefully closes the connection pool and any replica pools, or the
+// SQLite database.
 func Close(log *zap.Logger) {
+	closeReplicas(log)
+	if poolMetrics != nil {
+		_ = poolMetrics.Unregister()
+	}
+	closeSQLite(log)
 	if pool != nil {
 		pool.Close()
 		log.Info("PostgreSQL connection pool closed")
 	}
 }
-
-// RunMigrations creates the initial schema if it does not exist.
-func RunMigrations(ctx context.Context, log *zap.Logger) error {
-	query := `
-	CREATE TABLE IF NOT EXISTS users (
-		id SERIAL PRIMARY KEY,
-		name VARCHAR(255) NOT NULL,
-		email VARCHAR(255) NOT NULL UNIQUE,
-		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-	);
-	`
-
-	if _, err := pool.Exec(ctx, query); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
-	}
-
-	log.Info("Database migrations completed")
-	return nil
-}
-
-// HealthCheck pings the database and returns an error if unhealthy.
-func HealthCheck(ctx context.Context) error {
-	return pool.Ping(ctx)
-}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"gofiberobservability/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const sqlstateClassKey = attribute.Key("db.sqlstate.class")
+
+type operationKey struct{}
+
+// WithOperation names the database work done with ctx (e.g. "users.list").
+// The name becomes db.operation.name on query metrics, so keep it to a fixed
+// set; queries without one are labeled by their SQL verb.
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+// operationName is db.operation.name for sql run with ctx: the WithOperation
+// name, or the SQL verb. It also names query spans ("query users.get").
+func operationName(ctx context.Context, sql string) string {
+	if op, _ := ctx.Value(operationKey{}).(string); op != "" {
+		return op
+	}
+	return sqlVerb(sql)
+}
+
+// queryMetricsTracer is a pgx tracer recording per-operation latency and
+// errors. It runs next to otelpgx in a multitracer chain; the SQLite backend
+// calls start and end directly.
+type queryMetricsTracer struct {
+	duration   metric.Float64Histogram
+	errors     metric.Int64Counter
+	systemAttr attribute.KeyValue
+	poolAttr   attribute.KeyValue
+}
+
+type queryStart struct {
+	operation string
+	start     time.Time
+}
+
+type queryStartKey struct{}
+
+func newQueryMetricsTracer(system attribute.KeyValue, poolName string) (*queryMetricsTracer, error) {
+	meter := metrics.GetMeter()
+
+	duration, err := meter.Float64Histogram("db.client.operation.duration",
+		metric.WithDescription("Duration of database client operations"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10),
+	)
+	if err != nil {
+		return nil, err
+	}
+	errs, err := meter.Int64Counter("db.client.operation.errors",
+		metric.WithDescription("Failed database client operations, by SQLSTATE class"),
+		metric.WithUnit("{error}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &queryMetricsTracer{
+		duration:   duration,
+		errors:     errs,
+		systemAttr: system,
+		poolAttr:   poolNameKey.String(poolName),
+	}, nil
+}
+
+func (t *queryMetricsTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return t.start(ctx, data.SQL)
+}
+
+func (t *queryMetricsTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.end(ctx, data.Err)
+}
+
+func (t *queryMetricsTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return t.start(ctx, "batch")
+}
+
+func (t *queryMetricsTracer) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+func (t *queryMetricsTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	t.end(ctx, data.Err)
+}
+
+func (t *queryMetricsTracer) start(ctx context.Context, sql string) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{operation: operationName(ctx, sql), start: time.Now()})
+}
+
+func (t *queryMetricsTracer) end(ctx context.Context, err error) {
+	qs, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		t.systemAttr,
+		semconv.DBOperationName(qs.operation),
+		t.poolAttr,
+	}
+	if err != nil {
+		class, errType := classifyError(err)
+		attrs = append(attrs, semconv.ErrorTypeKey.String(errType))
+		t.errors.Add(ctx, 1, metric.WithAttributes(append(attrs, sqlstateClassKey.String(class))...))
+	}
+	t.duration.Record(ctx, time.Since(qs.start).Seconds(), metric.WithAttributes(attrs...))
+}
+
+// sqlstateClasses names the SQLSTATE classes worth telling apart on a dashboard.
+var sqlstateClasses = map[string]string{
+	"08": "connection_exception",
+	"22": "data_exception",
+	"23": "integrity_constraint_violation",
+	"25": "invalid_transaction_state",
+	"28": "invalid_authorization",
+	"40": "transaction_rollback",
+	"42": "syntax_error_or_access_rule_violation",
+	"53": "insufficient_resources",
+	"54": "program_limit_exceeded",
+	"55": "object_not_in_prerequisite_state",
+	"57": "operator_intervention",
+	"58": "system_error",
+	"XX": "internal_error",
+}
+
+// classifyError returns the SQLSTATE class (first two characters of the code)
+// and a low-cardinality error.type. Errors that never reached the server get
+// class "none".
+func classifyError(err error) (class, errType string) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && len(pgErr.Code) >= 2 {
+		class = pgErr.Code[:2]
+		if name, ok := sqlstateClasses[class]; ok {
+			return class, name
+		}
+		return class, "sqlstate_" + class
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "none", "timeout"
+	case errors.Is(err, context.Canceled):
+		return "none", "canceled"
+	case pgconn.SafeToRetry(err):
+		return "none", "connection"
+	}
+	return "none", "other"
+}
+
+// sqlVerb is the fallback operation name: the statement's leading keyword.
+func sqlVerb(sql string) string {
+	sql = strings.TrimSpace(sql)
+	verb := sql
+	if i := strings.IndexFunc(sql, unicode.IsSpace); i >= 0 {
+		verb = sql[:i]
+	}
+	switch verb = strings.ToLower(verb); verb {
+	case "select", "insert", "update", "delete", "with", "create", "alter", "drop",
+		"begin", "commit", "rollback", "savepoint", "release", "set", "copy", "lock", "pragma":
+		return verb
+	}
+	return "other"
+}
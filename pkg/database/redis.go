@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"gofiberobservability/pkg/breaker"
 	"gofiberobservability/pkg/config"
 
 	redisotel "github.com/redis/go-redis/extra/redisotel/v9"
@@ -11,10 +12,21 @@ import (
 	"go.uber.org/zap"
 )
 
-var rdb *redis.Client
+var (
+	rdb           *redis.Client
+	redisBreaker  *breaker.Breaker
+	redisDisabled bool
+)
 
 // InitRedis initializes the Redis connection pool with OpenTelemetry instrumentation.
+// An empty RedisURL leaves Redis out: GetRedis returns nil.
 func InitRedis(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
+	if cfg.RedisURL == "" {
+		redisDisabled = true
+		log.Info("Redis not configured, running without cache")
+		return nil
+	}
+
 	opt, err := redis.ParseURL(cfg.RedisURL)
 	if err != nil {
 		return fmt.Errorf("failed to parse Redis URL: %w", err)
@@ -30,11 +42,22 @@ func InitRedis(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
 		return fmt.Errorf("failed to instrument Redis metrics: %w", err)
 	}
 
-	// Verify connection
-	if err := rdb.Ping(ctx).Err(); err != nil {
+	// Verify connection, waiting for a Redis that is still starting up
+	err = retryStartup(ctx, cfg, log, "Redis", func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	})
+	if err != nil {
 		return fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
+	// Fail fast at runtime while Redis is down. Added after the otel hooks,
+	// so rejected commands still appear in traces and metrics.
+	redisBreaker, err = newBreaker("redis", cfg)
+	if err != nil {
+		return err
+	}
+	rdb.AddHook(breakerHook{br: redisBreaker})
+
 	log.Info("Redis initialized",
 		zap.String("addr", opt.Addr),
 		zap.Int("db", opt.DB),
@@ -58,11 +81,3 @@ func CloseRedis(log *zap.Logger) {
 		}
 	}
 }
-
-// RedisHealthCheck checks the health of the Redis connection.
-func RedisHealthCheck(ctx context.Context) error {
-	if rdb == nil {
-		return fmt.Errorf("redis client not initialized")
-	}
-	return rdb.Ping(ctx).Err()
-}
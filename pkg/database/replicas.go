@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gofiberobservability/pkg/breaker"
+	"gofiberobservability/pkg/config"
+	"gofiberobservability/pkg/metrics"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// replica is a read-only node with its own pool and circuit breaker. healthy
+// is flipped by the health loop; reads skip unhealthy replicas.
+type replica struct {
+	name    string
+	db      *GuardedPool
+	metrics metric.Registration
+	healthy atomic.Bool
+}
+
+var (
+	replicas       []*replica
+	replicaNext    atomic.Uint64
+	replicaHealth  metric.Registration
+	replicaStop    context.CancelFunc
+	replicaWatcher sync.WaitGroup
+)
+
+type primaryKey struct{}
+
+type sessionKey struct{}
+
+// session tracks whether a write was made in a read-your-writes scope.
+type session struct {
+	wrote atomic.Bool
+}
+
+// WithPrimary sends every read made with ctx to the primary.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// WithReadYourWrites starts a read-your-writes scope: once MarkWrite is called
+// with ctx (or a context derived from it), reads in the scope go to the
+// primary, so they see the write regardless of replication lag.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// MarkWrite records a write in the read-your-writes scope of ctx, if any.
+func MarkWrite(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+	}
+}
+
+// Reader returns the pool a read-only query made with ctx should run on: the
+// next healthy replica with a closed breaker in round-robin order, or the
+// primary when there is none, the ctx asks for it (WithPrimary) or it already
+// wrote (WithReadYourWrites + MarkWrite).
+func Reader(ctx context.Context) *GuardedPool {
+	if len(replicas) == 0 || ctx.Value(primaryKey{}) != nil {
+		return primary
+	}
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok && s.wrote.Load() {
+		return primary
+	}
+
+	start := replicaNext.Add(1)
+	for i := range uint64(len(replicas)) {
+		r := replicas[(start+i)%uint64(len(replicas))]
+		if r.healthy.Load() && r.db.br.State() == breaker.StateClosed {
+			return r.db
+		}
+	}
+	return primary
+}
+
+// initReplicas opens a pool per replica and starts the health loop. A replica
+// that is down at startup is not fatal: it stays out of rotation until a
+// health check succeeds.
+func initReplicas(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
+	for i, dsn := range cfg.DatabaseReplicaURLs {
+		name := fmt.Sprintf("replica-%d", i+1)
+		p, pgxCfg, err := newPool(ctx, cfg, dsn, name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		br, err := newBreaker("postgres."+name, cfg)
+		if err != nil {
+			p.Close()
+			return err
+		}
+		r := &replica{name: name, db: newGuardedPool(p, br, cfg.DBStatementTimeout)}
+		replicas = append(replicas, r)
+
+		if r.metrics, err = registerPoolMetrics(p, name); err != nil {
+			return fmt.Errorf("failed to register %s pool metrics: %w", name, err)
+		}
+
+		if err := p.Ping(ctx); err != nil {
+			log.Warn("Read replica unreachable, keeping it out of rotation",
+				zap.String("replica", name), zap.String("host", pgxCfg.ConnConfig.Host), zap.Error(err))
+		} else {
+			r.healthy.Store(true)
+		}
+
+		log.Info("Read replica pool initialized",
+			zap.String("replica", name),
+			zap.String("host", pgxCfg.ConnConfig.Host),
+			zap.Uint16("port", pgxCfg.ConnConfig.Port),
+			zap.Bool("healthy", r.healthy.Load()),
+		)
+	}
+
+	var err error
+	if replicaHealth, err = registerReplicaMetrics(); err != nil {
+		return fmt.Errorf("failed to register replica metrics: %w", err)
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	replicaStop = cancel
+	replicaWatcher.Add(1)
+	go func() {
+		defer replicaWatcher.Done()
+		watchReplicas(watchCtx, cfg.DatabaseReplicaHealthInterval, log)
+	}()
+
+	return nil
+}
+
+// watchReplicas pings every replica each interval and logs health changes.
+// Pings go through the replica's breaker, so they also serve as its
+// half-open probe.
+func watchReplicas(ctx context.Context, interval time.Duration, log *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, r := range replicas {
+			pingCtx, cancel := context.WithTimeout(ctx, interval)
+			err := r.db.Ping(WithOperation(pingCtx, "health.ping"))
+			cancel()
+
+			healthy := err == nil
+			if r.healthy.Swap(healthy) == healthy {
+				continue
+			}
+			if healthy {
+				log.Info("Read replica back in rotation", zap.String("replica", r.name))
+			} else {
+				log.Warn("Read replica failed health check, removed from rotation",
+					zap.String("replica", r.name), zap.Error(err))
+			}
+		}
+	}
+}
+
+// registerReplicaMetrics exports whether each replica is in rotation.
+func registerReplicaMetrics() (metric.Registration, error) {
+	meter := metrics.GetMeter()
+
+	healthy, err := meter.Int64ObservableGauge("db.client.replica.healthy",
+		metric.WithDescription("Whether a read replica passed its last health check (1) or is out of rotation (0)"),
+		metric.WithUnit("{replica}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, r := range replicas {
+			var v int64
+			if r.healthy.Load() {
+				v = 1
+			}
+			o.ObserveInt64(healthy, v, metric.WithAttributes(poolNameKey.String(r.name)))
+		}
+		return nil
+	}, healthy)
+}
+
+func closeReplicas(log *zap.Logger) {
+	if replicaStop != nil {
+		replicaStop()
+		replicaWatcher.Wait()
+	}
+	if replicaHealth != nil {
+		_ = replicaHealth.Unregister()
+	}
+	for _, r := range replicas {
+		if r.metrics != nil {
+			_ = r.metrics.Unregister()
+		}
+		r.db.pool.Close()
+		log.Info("Read replica pool closed", zap.String("replica", r.name))
+	}
+	replicas = nil
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"gofiberobservability/pkg/config"
+
+	"go.uber.org/zap"
+)
+
+// startupAttemptTimeout bounds a single connection attempt, so a dependency
+// that accepts TCP but never answers doesn't eat the whole retry budget.
+const startupAttemptTimeout = 5 * time.Second
+
+// retryStartup calls connect until it succeeds or ctx is done, sleeping with
+// exponential backoff and jitter in between. This lets the service start
+// before its dependencies (e.g. docker-compose bringing Postgres up later).
+// A zero StartupRetryBackoff makes a single attempt.
+func retryStartup(ctx context.Context, cfg *config.Config, log *zap.Logger, dependency string, connect func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, startupAttemptTimeout)
+		err := connect(attemptCtx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				log.Info(dependency+" reachable", zap.Int("attempts", attempt))
+			}
+			return nil
+		}
+		if cfg.StartupRetryBackoff <= 0 {
+			return err
+		}
+
+		d := jitteredBackoff(attempt, cfg.StartupRetryBackoff, cfg.StartupRetryMaxBackoff)
+		log.Warn(dependency+" not reachable, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", d),
+			zap.Error(err),
+		)
+
+		if sleep(ctx, d) != nil {
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		}
+	}
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// jitteredBackoff doubles base per attempt up to maxBackoff, with equal jitter.
+func jitteredBackoff(attempt int, base, maxBackoff time.Duration) time.Duration {
+	d := base << (attempt - 1)
+	if d <= 0 || (maxBackoff > 0 && d > maxBackoff) {
+		d = maxBackoff
+	}
+	if d <= 0 {
+		return base
+	}
+	// Equal jitter: keep half the interval, randomize the rest
+	return d/2 + rand.N(d/2+1)
+}
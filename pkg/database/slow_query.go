@@ -0,0 +1,218 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"gofiberobservability/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxLoggedSQLLength caps the normalized statement in log lines.
+	maxLoggedSQLLength = 2000
+	// maxConcurrentExplains bounds the extra load EXPLAIN puts on a struggling
+	// database; slow queries beyond it are logged without a plan.
+	maxConcurrentExplains = 2
+	explainTimeout        = 5 * time.Second
+)
+
+// slowQueryTracer is a pgx tracer that logs queries slower than threshold
+// and, for a sampled share of them, captures the plan with EXPLAIN (FORMAT
+// JSON) in the background. It runs first in the multitracer chain, so the
+// otelpgx query span is still open when the query ends.
+type slowQueryTracer struct {
+	threshold   time.Duration
+	explainRate float64
+	poolName    string
+	// pool runs EXPLAIN; set once the pool exists.
+	pool    *pgxpool.Pool
+	explain chan struct{}
+}
+
+type slowQueryStart struct {
+	sql   string
+	args  []any
+	start time.Time
+}
+
+type slowQueryStartKey struct{}
+
+type explainKey struct{}
+
+func newSlowQueryTracer(threshold time.Duration, explainRate float64, poolName string) *slowQueryTracer {
+	return &slowQueryTracer{
+		threshold:   threshold,
+		explainRate: explainRate,
+		poolName:    poolName,
+		explain:     make(chan struct{}, maxConcurrentExplains),
+	}
+}
+
+func (t *slowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if ctx.Value(explainKey{}) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, slowQueryStartKey{}, slowQueryStart{sql: data.SQL, args: data.Args, start: time.Now()})
+}
+
+func (t *slowQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	qs, ok := ctx.Value(slowQueryStartKey{}).(slowQueryStart)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(qs.start)
+	if elapsed < t.threshold {
+		return
+	}
+
+	op := operationName(ctx, qs.sql)
+	sql := normalizeSQL(qs.sql)
+	logSlowQuery(ctx, t.poolName, op, sql, elapsed, data.CommandTag.RowsAffected(), data.Err)
+
+	span := trace.SpanFromContext(ctx)
+	if t.pool != nil && t.explainRate > 0 && rand.Float64() < t.explainRate && explainable(qs.sql) {
+		select {
+		case t.explain <- struct{}{}:
+			go func() {
+				defer func() { <-t.explain }()
+				t.runExplain(span.SpanContext(), op, sql, qs)
+			}()
+		default:
+			// Enough plans in flight already
+		}
+	}
+}
+
+// logSlowQuery logs a query slower than the threshold and flags its span,
+// which must still be open in ctx. sql is already normalized.
+func logSlowQuery(ctx context.Context, poolName, op, sql string, elapsed time.Duration, rows int64, err error) {
+	fields := []zap.Field{
+		zap.String("db.operation.name", op),
+		zap.String("db.query.text", sql),
+		zap.Duration("duration", elapsed),
+		zap.Int64("rows", rows),
+		zap.String("pool", poolName),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	logger.GetLoggerWithTraceContext(ctx).Warn("Slow query", fields...)
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("db.query.slow", true))
+}
+
+// runExplain captures the plan of a slow query. The query span has ended by
+// now, so the plan goes on a db.explain span linked to it and on a log line
+// carrying the original trace_id.
+func (t *slowQueryTracer) runExplain(query trace.SpanContext, op, sql string, qs slowQueryStart) {
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+
+	ctx, span := dbTracer.Start(ctx, "db.explain",
+		trace.WithLinks(trace.Link{SpanContext: query}),
+		trace.WithAttributes(attribute.String("db.operation.name", op)),
+	)
+	defer span.End()
+
+	ctx = context.WithValue(WithOperation(ctx, "db.explain"), explainKey{}, true)
+
+	var plan []byte
+	err := t.pool.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+qs.sql, qs.args...).Scan(&plan)
+
+	log := logger.GetLogger().With(
+		zap.String("trace_id", query.TraceID().String()),
+		zap.String("span_id", query.SpanID().String()),
+		zap.String("db.operation.name", op),
+		zap.String("db.query.text", sql),
+	)
+	if err != nil {
+		span.RecordError(err)
+		log.Warn("Failed to explain slow query", zap.Error(err))
+		return
+	}
+
+	var compact bytes.Buffer
+	if json.Compact(&compact, plan) == nil {
+		plan = compact.Bytes()
+	}
+	span.AddEvent("db.query.plan", trace.WithAttributes(attribute.String("db.query.plan", string(plan))))
+	log.Info("Slow query plan", zap.Any("plan", json.RawMessage(plan)))
+}
+
+// explainable limits EXPLAIN to single DML statements; EXPLAIN without
+// ANALYZE doesn't execute them.
+func explainable(sql string) bool {
+	if strings.Contains(strings.TrimRight(strings.TrimSpace(sql), ";"), ";") {
+		return false
+	}
+	switch sqlVerb(sql) {
+	case "select", "with", "insert", "update", "delete":
+		return true
+	}
+	return false
+}
+
+// normalizeSQL collapses whitespace and replaces string and numeric literals
+// with ?, so slow queries group by shape and don't leak inlined values.
+// Placeholders ($1) and identifiers are kept.
+func normalizeSQL(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+
+	space := false
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = b.Len() > 0
+			continue
+		case c == '\'':
+			// Skip to the closing quote; '' is an escaped quote
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			c = '?'
+		case isDigit(c) && (i == 0 || !isIdentChar(sql[i-1])):
+			for i+1 < len(sql) && (isDigit(sql[i+1]) || sql[i+1] == '.' || sql[i+1] == 'e' || sql[i+1] == 'E') {
+				i++
+			}
+			c = '?'
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteByte(c)
+	}
+
+	out := b.String()
+	if len(out) > maxLoggedSQLLength {
+		out = out[:maxLoggedSQLLength] + "..."
+	}
+	return out
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentChar includes $ so placeholders like $1 are not treated as literals.
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
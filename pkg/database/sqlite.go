@@ -0,0 +1,376 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gofiberobservability/pkg/config"
+	"gofiberobservability/pkg/metrics"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// sqliteDriverName is the database/sql driver SQLite URLs open with. The
+// pure-Go modernc.org/sqlite driver registers it in builds with -tags sqlite.
+const sqliteDriverName = "sqlite"
+
+const (
+	sqliteRowsAffectedKey    = attribute.Key("sqlite.rows_affected")
+	sqliteQueryParametersKey = attribute.Key("sqlite.query.parameters")
+)
+
+// sqlitePragmas run once on the connection. WAL lets readers proceed during
+// a write; busy_timeout waits for locks held by other processes (e.g. a
+// sqlite3 shell) instead of failing with SQLITE_BUSY.
+var sqlitePragmas = []string{
+	"PRAGMA foreign_keys = ON",
+	"PRAGMA busy_timeout = 5000",
+	"PRAGMA journal_mode = WAL",
+}
+
+var sqliteDB *SQLiteDB
+
+// initSQLite opens the SQLite database named by cfg.DatabaseURL
+// (sqlite://path/to/app.db, sqlite://:memory: or a file: URI).
+func initSQLite(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
+	dsn := sqliteDSN(cfg.DatabaseURL)
+	db, err := sql.Open(sqliteDriverName, dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+
+	// SQLite has a single writer: one connection avoids SQLITE_BUSY between
+	// our own queries and keeps a :memory: database alive. The connection is
+	// never recycled, so the pragmas stick.
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	for _, pragma := range sqlitePragmas {
+		if _, err := db.ExecContext(ctx, pragma); err != nil {
+			db.Close()
+			return fmt.Errorf("failed to configure SQLite (%s): %w", pragma, err)
+		}
+	}
+
+	queryMetrics, err := newQueryMetricsTracer(semconv.DBSystemSqlite, primaryPoolName)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("failed to create query metrics: %w", err)
+	}
+	sqliteDB = &SQLiteDB{
+		sqliteQuerier: sqliteQuerier{conn: db, obs: &sqliteObserver{
+			metrics:       queryMetrics,
+			slowThreshold: cfg.DBSlowQueryThreshold,
+		}},
+		db:  db,
+		dsn: dsn,
+	}
+
+	poolMetrics, err = registerSQLPoolMetrics(db, primaryPoolName)
+	if err != nil {
+		return fmt.Errorf("failed to register pool metrics: %w", err)
+	}
+
+	if len(cfg.DatabaseReplicaURLs) > 0 {
+		log.Warn("Read replicas are not supported with SQLite; ignoring DATABASE_REPLICA_URLS")
+	}
+	log.Info("SQLite database opened", zap.String("path", dsn))
+	return nil
+}
+
+// sqliteDSN strips the sqlite:// scheme; file: URIs go to the driver as-is.
+func sqliteDSN(url string) string {
+	if rest, ok := strings.CutPrefix(url, "sqlite://"); ok {
+		return rest
+	}
+	return strings.TrimPrefix(url, "sqlite:")
+}
+
+// SQLite returns the SQLite database, or nil when running on PostgreSQL.
+func SQLite() *SQLiteDB {
+	return sqliteDB
+}
+
+func closeSQLite(log *zap.Logger) {
+	if sqliteDB == nil {
+		return
+	}
+	if err := sqliteDB.db.Close(); err != nil {
+		log.Error("Failed to close SQLite database", zap.Error(err))
+		return
+	}
+	log.Info("SQLite database closed")
+}
+
+// SQLiteDB is a SQLite database instrumented like the pgx pools: a client
+// span per query (when the caller's span is recording), the
+// db.client.operation.* metrics with db.system=sqlite, and the slow-query
+// log. Queries don't get the statement timeout: there is no network to hang
+// on, and busy_timeout bounds lock waits.
+type SQLiteDB struct {
+	sqliteQuerier
+	db  *sql.DB
+	dsn string
+}
+
+// DB returns the underlying handle, bypassing instrumentation.
+func (d *SQLiteDB) DB() *sql.DB {
+	return d.db
+}
+
+// Begin starts a transaction. While it is open it holds the only
+// connection, so all queries must go through it.
+func (d *SQLiteDB) Begin(ctx context.Context) (*SQLiteTx, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteTx{sqliteQuerier: sqliteQuerier{conn: tx, obs: d.obs}, tx: tx}, nil
+}
+
+// SQLiteTx is a transaction, or a savepoint within one when it was begun
+// from another SQLiteTx.
+type SQLiteTx struct {
+	sqliteQuerier
+	tx        *sql.Tx
+	savepoint string
+	depth     int
+	done      bool
+}
+
+// Begin opens a savepoint in the transaction.
+func (t *SQLiteTx) Begin(ctx context.Context) (*SQLiteTx, error) {
+	name := fmt.Sprintf("sp_%d", t.depth+1)
+	if _, err := t.Exec(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &SQLiteTx{sqliteQuerier: t.sqliteQuerier, tx: t.tx, savepoint: name, depth: t.depth + 1}, nil
+}
+
+// Commit commits the transaction or releases the savepoint.
+func (t *SQLiteTx) Commit(ctx context.Context) error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if t.savepoint == "" {
+		return t.tx.Commit()
+	}
+	_, err := t.Exec(ctx, "RELEASE SAVEPOINT "+t.savepoint)
+	return err
+}
+
+// Rollback rolls back the transaction or savepoint. After Commit it is a
+// no-op returning sql.ErrTxDone, so it can be deferred.
+func (t *SQLiteTx) Rollback(ctx context.Context) error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if t.savepoint == "" {
+		return t.tx.Rollback()
+	}
+	if _, err := t.Exec(ctx, "ROLLBACK TO SAVEPOINT "+t.savepoint); err != nil {
+		return err
+	}
+	_, err := t.Exec(ctx, "RELEASE SAVEPOINT "+t.savepoint)
+	return err
+}
+
+// sqliteConn is what *sql.DB and *sql.Tx have in common.
+type sqliteConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// sqliteQuerier gives SQLiteDB and SQLiteTx the same instrumented methods.
+type sqliteQuerier struct {
+	conn sqliteConn
+	obs  *sqliteObserver
+}
+
+func (q sqliteQuerier) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, end := q.obs.start(ctx, query, args)
+	res, err := q.conn.ExecContext(ctx, query, args...)
+	var rows int64
+	if err == nil {
+		rows, _ = res.RowsAffected()
+	}
+	end(rows, err)
+	return res, err
+}
+
+// Query keeps the span open until the rows are done.
+func (q sqliteQuerier) Query(ctx context.Context, query string, args ...any) (*SQLiteRows, error) {
+	ctx, end := q.obs.start(ctx, query, args)
+	rows, err := q.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		end(0, err)
+		return nil, err
+	}
+	return &SQLiteRows{Rows: rows, end: end}, nil
+}
+
+// QueryRow keeps the span open until Scan, where database/sql reports the
+// statement's errors (constraint violations, sql.ErrNoRows).
+func (q sqliteQuerier) QueryRow(ctx context.Context, query string, args ...any) *SQLiteRow {
+	ctx, end := q.obs.start(ctx, query, args)
+	return &SQLiteRow{row: q.conn.QueryRowContext(ctx, query, args...), end: end}
+}
+
+// SQLiteRow ends the query span and metrics in Scan.
+type SQLiteRow struct {
+	row   *sql.Row
+	end   func(rows int64, err error)
+	ended bool
+}
+
+func (r *SQLiteRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	if !r.ended {
+		r.ended = true
+		var rows int64
+		if err == nil {
+			rows = 1
+		}
+		r.end(rows, err)
+	}
+	return err
+}
+
+// SQLiteRows ends the query span and metrics when iteration finishes or the
+// rows are closed.
+type SQLiteRows struct {
+	*sql.Rows
+	end   func(rows int64, err error)
+	count int64
+	ended bool
+}
+
+func (r *SQLiteRows) Next() bool {
+	if r.Rows.Next() {
+		r.count++
+		return true
+	}
+	r.finish(r.Rows.Err())
+	return false
+}
+
+func (r *SQLiteRows) Close() error {
+	err := r.Rows.Close()
+	r.finish(err)
+	return err
+}
+
+func (r *SQLiteRows) finish(err error) {
+	if !r.ended {
+		r.ended = true
+		r.end(r.count, err)
+	}
+}
+
+// sqliteObserver does for SQLite what otelpgx, queryMetricsTracer and
+// slowQueryTracer do for pgx.
+type sqliteObserver struct {
+	metrics       *queryMetricsTracer
+	slowThreshold time.Duration
+}
+
+// start begins the query span and metrics; the returned func ends them with
+// the number of rows affected or returned.
+func (o *sqliteObserver) start(ctx context.Context, query string, args []any) (context.Context, func(rows int64, err error)) {
+	ctx = o.metrics.start(ctx, query)
+	begin := time.Now()
+
+	var span trace.Span
+	if trace.SpanFromContext(ctx).IsRecording() {
+		params := make([]string, len(args))
+		for i, a := range args {
+			params[i] = fmt.Sprintf("%+v", a)
+		}
+		ctx, span = dbTracer.Start(ctx, "query "+operationName(ctx, query),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemSqlite,
+				poolNameKey.String(primaryPoolName),
+				semconv.DBQueryText(query),
+				semconv.DBOperationName(strings.ToUpper(sqlVerb(query))),
+				sqliteQueryParametersKey.StringSlice(params),
+			),
+		)
+	}
+
+	return ctx, func(rows int64, err error) {
+		// An empty lookup is an answer, not a failed operation (as with pgx)
+		noRows := errors.Is(err, sql.ErrNoRows)
+		if noRows {
+			o.metrics.end(ctx, nil)
+		} else {
+			o.metrics.end(ctx, err)
+		}
+
+		if elapsed := time.Since(begin); o.slowThreshold > 0 && elapsed >= o.slowThreshold {
+			logSlowQuery(ctx, primaryPoolName, operationName(ctx, query), normalizeSQL(query), elapsed, rows, err)
+		}
+
+		if span == nil {
+			return
+		}
+		switch {
+		case noRows:
+			span.RecordError(err)
+			span.SetAttributes(sqliteRowsAffectedKey.Int64(0))
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		default:
+			span.SetAttributes(sqliteRowsAffectedKey.Int64(rows))
+		}
+		span.End()
+	}
+}
+
+// registerSQLPoolMetrics exports the database/sql connection counts under
+// the same names as the pgxpool statistics.
+func registerSQLPoolMetrics(db *sql.DB, poolName string) (metric.Registration, error) {
+	meter := metrics.GetMeter()
+
+	connCount, err := meter.Int64ObservableUpDownCounter("db.client.connection.count",
+		metric.WithDescription("Connections in the pool by state (idle, used, constructing)"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	connMax, err := meter.Int64ObservableUpDownCounter("db.client.connection.max",
+		metric.WithDescription("Maximum number of connections allowed in the pool"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	poolAttr := poolNameKey.String(poolName)
+	stateAttrs := func(state string) metric.ObserveOption {
+		return metric.WithAttributes(poolAttr, attribute.String("db.client.connection.state", state))
+	}
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		s := db.Stats()
+		o.ObserveInt64(connCount, int64(s.Idle), stateAttrs("idle"))
+		o.ObserveInt64(connCount, int64(s.InUse), stateAttrs("used"))
+		o.ObserveInt64(connMax, int64(s.MaxOpenConnections), metric.WithAttributes(poolAttr))
+		return nil
+	}, connCount, connMax)
+}
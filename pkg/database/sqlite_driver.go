@@ -0,0 +1,5 @@
+package database
+
+// Pure-Go SQLite driver (no cgo), registered as "sqlite", so every build can
+// run on a sqlite:// DATABASE_URL.
+import _ "modernc.org/sqlite"
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const createSQLiteMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version      INTEGER PRIMARY KEY,
+	name         TEXT NOT NULL,
+	checksum     TEXT NOT NULL,
+	applied_at   TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+	execution_ms INTEGER NOT NULL DEFAULT 0
+)`
+
+// migrateSQLite applies pending migrations to a SQLite database, using the
+// shared migration set with the overrides in migrations/sqlite. There is no
+// lock to take: a SQLite file belongs to a single instance, and the only
+// connection is held by each migration's transaction.
+func migrateSQLite(ctx context.Context, db *SQLiteDB, log *zap.Logger) error {
+	migrations, err := LoadDialectMigrations(migrationFiles, "migrations", "sqlite")
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(WithOperation(ctx, "schema.migrate"), createSQLiteMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := sqliteApplied(ctx, db)
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksums(migrations, applied); err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, mig := range migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		pending++
+		if err := runSQLiteMigration(ctx, db, log, mig); err != nil {
+			return err
+		}
+	}
+	if pending == 0 {
+		log.Info("Database schema is up to date", zap.Int64("version", maxVersion(applied)))
+	}
+	return nil
+}
+
+// runSQLiteMigration applies one migration and records it in a transaction,
+// traced like Migrator.run.
+func runSQLiteMigration(ctx context.Context, db *SQLiteDB, log *zap.Logger, mig Migration) (err error) {
+	ctx, span := dbTracer.Start(ctx, "db.migrate up", trace.WithAttributes(
+		attribute.Int64("db.migration.version", mig.Version),
+		attribute.String("db.migration.name", mig.Name),
+		attribute.String("db.migration.direction", "up"),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	ctx = WithOperation(ctx, "schema.migrate")
+	start := time.Now()
+
+	err = func() error {
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if _, err := tx.Exec(ctx, mig.Up); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx,
+			"INSERT INTO schema_migrations (version, name, checksum, execution_ms) VALUES (?, ?, ?, ?)",
+			mig.Version, mig.Name, mig.Checksum, time.Since(start).Milliseconds()); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}()
+
+	log = log.With(
+		zap.Int64("version", mig.Version),
+		zap.String("name", mig.Name),
+		zap.String("direction", "up"),
+		zap.Duration("duration", time.Since(start)),
+	)
+	if err != nil {
+		log.Error("Migration failed, rolled back", zap.Error(err))
+		return fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+	}
+	log.Info("Migration completed")
+	return nil
+}
+
+// sqliteApplied reads schema_migrations. applied_at is left zero: drivers
+// disagree on how they return SQLite timestamps, and nothing here needs it.
+func sqliteApplied(ctx context.Context, db *SQLiteDB) (map[int64]appliedMigration, error) {
+	rows, err := db.Query(WithOperation(ctx, "schema.status"), "SELECT version, name, checksum FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var version int64
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.name, &a.checksum); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gofiberobservability/pkg/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// queryExecModes maps DB_QUERY_EXEC_MODE values to pgx modes. Behind
+// PgBouncer in transaction mode, prepared statements don't survive between
+// transactions: use exec or simple_protocol there.
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// applyStatementConfig sets the query exec mode and server-side
+// statement_timeout. Both are left alone when unset in cfg, so DSN
+// parameters (default_query_exec_mode, statement_timeout) still apply.
+// statement_timeout goes out as a startup parameter, which PgBouncer refuses
+// unless it ignores it, so it is off by default.
+func applyStatementConfig(pgxCfg *pgxpool.Config, cfg *config.Config) error {
+	if cfg.DBQueryExecMode != "" {
+		mode, ok := queryExecModes[cfg.DBQueryExecMode]
+		if !ok {
+			return fmt.Errorf("unknown query exec mode %q", cfg.DBQueryExecMode)
+		}
+		pgxCfg.ConnConfig.DefaultQueryExecMode = mode
+	}
+
+	if cfg.DBServerStatementTimeout > 0 {
+		if _, ok := pgxCfg.ConnConfig.RuntimeParams["statement_timeout"]; !ok {
+			pgxCfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.DBServerStatementTimeout.Milliseconds(), 10)
+		}
+	}
+	return nil
+}
+
+type statementTimeoutKey struct{}
+
+// WithStatementTimeout overrides the default statement timeout for queries
+// made with ctx; 0 removes the default deadline. The server-side
+// statement_timeout still caps every statement.
+func WithStatementTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey{}, d)
+}
+
+// statementContext bounds ctx by the statement timeout. A deadline already on
+// ctx wins when it is earlier.
+func statementContext(ctx context.Context, defaultTimeout time.Duration) (context.Context, context.CancelFunc) {
+	timeout := defaultTimeout
+	if d, ok := ctx.Value(statementTimeoutKey{}).(time.Duration); ok {
+		timeout = d
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
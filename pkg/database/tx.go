@@ -0,0 +1,258 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gofiberobservability/pkg/metrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultTxMaxRetries = 3
+	txRetryBackoff      = 20 * time.Millisecond
+	txRetryMaxBackoff   = time.Second
+
+	txNameKey    = attribute.Key("db.transaction.name")
+	txOutcomeKey = attribute.Key("db.transaction.outcome")
+)
+
+// TxOptions configures WithTx.
+type TxOptions struct {
+	// Name labels the span and metrics (e.g. "users.merge"); keep it to a fixed set.
+	Name string
+	// IsoLevel and AccessMode default to the server's (read committed, read write).
+	IsoLevel   pgx.TxIsoLevel
+	AccessMode pgx.TxAccessMode
+	// MaxRetries bounds retries on serialization failures and deadlocks.
+	// 0 means 3; a negative value disables retries.
+	MaxRetries int
+	// DB is where the transaction begins; nil means Primary(), or the
+	// transaction carried by ctx. A pgx.Tx makes it a savepoint.
+	DB Beginner
+}
+
+// Beginner starts transactions. *pgxpool.Pool, *GuardedPool and pgx.Tx
+// (where Begin opens a savepoint) satisfy it.
+type Beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// txBeginner is a Beginner that can also set the isolation level and access mode.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+type txKey struct{}
+
+// WithTx runs fn in a transaction on opts.DB (the primary by default) and
+// commits if it returns nil. On an error or panic the transaction is rolled back (the panic is
+// re-raised). Serialization failures (40001) and deadlocks (40P01) roll back
+// and run fn again after a jittered backoff, so fn must not have side effects
+// outside the transaction.
+//
+// fn receives a ctx carrying the transaction: calling WithTx with it opens a
+// savepoint instead of a new transaction, which rolls back on its own when
+// the inner fn fails. Options other than Name don't apply to savepoints, and
+// retries happen only at the outermost level.
+//
+// WithTx is Postgres-only: in SQLite mode it returns an error, use
+// SQLite().Begin instead.
+func WithTx(ctx context.Context, opts TxOptions, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	name := opts.Name
+	if name == "" {
+		name = "transaction"
+	}
+	db := opts.DB
+	if db == nil {
+		if parent, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+			return withSavepoint(ctx, parent, name, fn)
+		}
+		primary := Primary()
+		if primary == nil {
+			if SQLite() != nil {
+				return errors.New("database.WithTx needs PostgreSQL; use SQLite().Begin with the SQLite backend")
+			}
+			return errors.New("database not initialized")
+		}
+		db = primary
+	}
+	if parent, ok := db.(pgx.Tx); ok {
+		return withSavepoint(ctx, parent, name, fn)
+	}
+
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultTxMaxRetries
+	}
+
+	m := getTxMetrics()
+	ctx, span := dbTracer.Start(ctx, "db.transaction "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			txNameKey.String(name),
+			attribute.String("db.transaction.isolation_level", isoLevelName(opts.IsoLevel)),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	nameAttr := txNameKey.String(name)
+
+	defer func() {
+		if p := recover(); p != nil {
+			span.SetStatus(codes.Error, fmt.Sprint(p))
+			span.SetAttributes(txOutcomeKey.String("panicked"))
+			m.duration.Record(ctx, time.Since(start).Seconds(),
+				metric.WithAttributes(nameAttr, txOutcomeKey.String("panicked")))
+			panic(p)
+		}
+	}()
+
+	begin := func(ctx context.Context) (pgx.Tx, error) {
+		if b, ok := db.(txBeginner); ok {
+			return b.BeginTx(ctx, pgx.TxOptions{IsoLevel: opts.IsoLevel, AccessMode: opts.AccessMode})
+		}
+		if opts.IsoLevel != "" || opts.AccessMode != "" {
+			return nil, fmt.Errorf("%T can't set the isolation level or access mode", db)
+		}
+		return db.Begin(ctx)
+	}
+
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		err = runTx(ctx, begin, fn)
+		reason := retryReason(err)
+		if reason == "" || attempt > maxRetries {
+			break
+		}
+
+		m.retries.Add(ctx, 1, metric.WithAttributes(nameAttr, semconv.ErrorTypeKey.String(reason)))
+		span.AddEvent("db.transaction.retry", trace.WithAttributes(
+			attribute.Int("db.transaction.attempt", attempt),
+			semconv.ErrorTypeKey.String(reason),
+		))
+
+		if sleep(ctx, jitteredBackoff(attempt, txRetryBackoff, txRetryMaxBackoff)) != nil {
+			break
+		}
+	}
+
+	outcome := "committed"
+	attrs := []attribute.KeyValue{nameAttr}
+	if err != nil {
+		outcome = "rolled_back"
+		_, errType := classifyError(err)
+		attrs = append(attrs, semconv.ErrorTypeKey.String(errType))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	attrs = append(attrs, txOutcomeKey.String(outcome))
+	span.SetAttributes(txOutcomeKey.String(outcome), attribute.Int("db.transaction.attempts", attempt))
+	m.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+
+	return err
+}
+
+// withSavepoint runs fn in a savepoint of the enclosing transaction.
+func withSavepoint(ctx context.Context, parent pgx.Tx, name string, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	ctx, span := dbTracer.Start(ctx, "db.savepoint "+name, trace.WithAttributes(txNameKey.String(name)))
+	defer span.End()
+
+	err := runTx(ctx, parent.Begin, fn)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// runTx is one attempt: begin, fn, then commit, rolling back on error or
+// panic. Rollback ignores ctx cancellation so the connection is returned clean.
+func runTx(ctx context.Context, begin func(context.Context) (pgx.Tx, error), fn func(ctx context.Context, tx pgx.Tx) error) error {
+	tx, err := begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx), tx); err != nil {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// retryReason returns the error.type of a retryable transaction error, or "".
+func retryReason(err error) string {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return ""
+	}
+	switch pgErr.Code {
+	case "40001":
+		return "serialization_failure"
+	case "40P01":
+		return "deadlock_detected"
+	}
+	return ""
+}
+
+func isoLevelName(level pgx.TxIsoLevel) string {
+	if level == "" {
+		return "default"
+	}
+	return string(level)
+}
+
+type txMetrics struct {
+	duration metric.Float64Histogram
+	retries  metric.Int64Counter
+}
+
+var (
+	dbTracer = otel.Tracer("gofiberobservability/database")
+
+	txMetricsOnce sync.Once
+	txMetricsInst *txMetrics
+)
+
+// getTxMetrics lazily registers the transaction instruments on first use.
+func getTxMetrics() *txMetrics {
+	txMetricsOnce.Do(func() {
+		meter := metrics.GetMeter()
+		m := &txMetrics{}
+
+		m.duration, _ = meter.Float64Histogram("db.client.transaction.duration",
+			metric.WithDescription("Duration of database transactions including retries, by outcome"),
+			metric.WithUnit("s"),
+			metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10),
+		)
+		m.retries, _ = meter.Int64Counter("db.client.transaction.retries",
+			metric.WithDescription("Transaction attempts retried after a serialization failure or deadlock"),
+			metric.WithUnit("{retry}"),
+		)
+
+		txMetricsInst = m
+	})
+	return txMetricsInst
+}
@@ -0,0 +1,268 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3/client"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// deadlineHeader hands the request's deadline (Unix nanoseconds) from the
+// request hook to FasthttpTransport, which removes it before sending.
+const deadlineHeader = "X-Httpclient-Deadline"
+
+// NewFiberClient returns a Fiber client with the same spans, metrics,
+// per-host timeouts, retries and circuit breaking as New. Retries stop at the
+// request's deadline: its context's or Request.SetTimeout's (the client-wide
+// SetTimeout isn't visible to hooks).
+func NewFiberClient(opts Options) *client.Client {
+	fc := &fasthttp.Client{
+		Transport: NewFasthttpTransport(nil, opts),
+		// Retries are ours, with backoff and the idempotency rules of New
+		MaxIdemponentCallAttempts: 1,
+	}
+	c := client.NewWithClient(fc).AddRequestHook(func(_ *client.Client, req *client.Request) error {
+		deadline, ok := req.Context().Deadline()
+		if timeout := req.Timeout(); timeout > 0 {
+			if d := time.Now().Add(timeout); !ok || d.Before(deadline) {
+				deadline, ok = d, true
+			}
+		}
+		if ok {
+			req.SetHeader(deadlineHeader, strconv.FormatInt(deadline.UnixNano(), 10))
+		}
+		return nil
+	})
+	return InstrumentFiberClient(c)
+}
+
+// InstrumentFiberClient adds a request hook that injects the global propagator
+// (traceparent, baggage) from the request context into Fiber client requests.
+// On its own that is all it does; NewFiberClient adds spans, metrics, retries
+// and circuit breaking on top.
+func InstrumentFiberClient(c *client.Client) *client.Client {
+	return c.AddRequestHook(func(_ *client.Client, req *client.Request) error {
+		otel.GetTextMapPropagator().Inject(req.Context(), fiberHeaderCarrier{req: req})
+		return nil
+	})
+}
+
+// FasthttpTransport is the fasthttp counterpart of Transport. fasthttp
+// requests carry no context, so the parent span is taken from the
+// traceparent that InstrumentFiberClient put in the headers, and replaced
+// with the attempt's own span; the deadline comes from NewFiberClient's
+// header, if any.
+type FasthttpTransport struct {
+	base fasthttp.RoundTripper
+	t    *Transport
+}
+
+var _ fasthttp.RoundTripper = (*FasthttpTransport)(nil)
+
+// NewFasthttpTransport wraps base (fasthttp.DefaultTransport when nil).
+func NewFasthttpTransport(base fasthttp.RoundTripper, opts Options) *FasthttpTransport {
+	if base == nil {
+		base = fasthttp.DefaultTransport
+	}
+	return &FasthttpTransport{base: base, t: NewTransport(nil, opts)}
+}
+
+// RoundTrip implements fasthttp.RoundTripper. It never asks fasthttp to
+// retry: retries happen here.
+func (f *FasthttpTransport) RoundTrip(hc *fasthttp.HostClient, req *fasthttp.Request, resp *fasthttp.Response) (bool, error) {
+	host := string(req.URI().Host())
+	br := f.t.breakerFor(host)
+	if err := br.Allow(); err != nil {
+		return false, fmt.Errorf("%s: %w", host, err)
+	}
+
+	ctx := context.Background()
+	if v := req.Header.Peek(deadlineHeader); len(v) > 0 {
+		if ns, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, time.Unix(0, ns))
+			defer cancel()
+		}
+		req.Header.Del(deadlineHeader)
+	}
+	parent := otel.GetTextMapPropagator().Extract(ctx, fasthttpHeaderCarrier{h: &req.Header})
+	retryable := f.t.opts.MaxRetries > 0 && !req.IsBodyStream() &&
+		isIdempotent(string(req.Header.Method()), string(req.Header.Peek("Idempotency-Key")))
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = f.attempt(parent, hc, req, resp, attempt)
+		if !retryable || attempt >= f.t.opts.MaxRetries || !(err != nil || isTransientStatus(resp.StatusCode())) || ctx.Err() != nil {
+			break
+		}
+		if f.t.backoff(ctx, attempt) != nil {
+			break
+		}
+		resp.Reset()
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		// Past the caller's deadline: says nothing about the host
+		br.Ignore()
+	case err != nil || resp.StatusCode() >= fasthttp.StatusInternalServerError:
+		br.Failure()
+	default:
+		br.Success()
+	}
+	return false, err
+}
+
+// attempt sends a single request with its own span, timeout and metric point.
+func (f *FasthttpTransport) attempt(parent context.Context, hc *fasthttp.HostClient, req *fasthttp.Request, resp *fasthttp.Response, resendCount int) error {
+	uri := req.URI()
+	method := string(req.Header.Method())
+	hostname, port := splitHostPort(string(uri.Host()), string(uri.Scheme()))
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.ServerAddress(hostname),
+	}
+	if port > 0 {
+		attrs = append(attrs, semconv.ServerPort(port))
+	}
+
+	spanAttrs := append([]attribute.KeyValue{semconv.URLFull(redactURL(uri.String()))}, attrs...)
+	if resendCount > 0 {
+		spanAttrs = append(spanAttrs, semconv.HTTPRequestResendCount(resendCount))
+	}
+
+	ctx, span := f.t.tracer.Start(parent, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(spanAttrs...),
+	)
+	defer span.End()
+
+	timeout := f.t.timeoutFor(string(uri.Host()), hostname)
+	if deadline, ok := parent.Deadline(); ok {
+		// Never wait past the caller's deadline (fasthttp treats <= 0 as none)
+		if left := max(time.Until(deadline), time.Millisecond); timeout <= 0 || left < timeout {
+			timeout = left
+		}
+	}
+	if timeout > 0 {
+		req.SetTimeout(timeout)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, fasthttpHeaderCarrier{h: &req.Header})
+
+	start := time.Now()
+	_, err := f.base.RoundTrip(hc, req, resp)
+	elapsed := time.Since(start)
+
+	var errType string
+	switch {
+	case err != nil:
+		errType = fasthttpErrorType(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case resp.StatusCode() >= fasthttp.StatusBadRequest:
+		errType = strconv.Itoa(resp.StatusCode())
+		span.SetStatus(codes.Error, fasthttp.StatusMessage(resp.StatusCode()))
+	}
+
+	var respAttrs []attribute.KeyValue
+	if err == nil {
+		respAttrs = append(respAttrs, semconv.HTTPResponseStatusCode(resp.StatusCode()))
+	}
+	if errType != "" {
+		respAttrs = append(respAttrs, semconv.ErrorTypeKey.String(errType))
+	}
+	span.SetAttributes(respAttrs...)
+
+	f.t.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(append(attrs, respAttrs...)...))
+
+	return err
+}
+
+func fasthttpErrorType(err error) string {
+	var netErr net.Error
+	if errors.Is(err, fasthttp.ErrTimeout) || errors.Is(err, fasthttp.ErrDialTimeout) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return "timeout"
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+func splitHostPort(host, scheme string) (string, int) {
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		port, _ := strconv.Atoi(p)
+		return h, port
+	}
+	switch scheme {
+	case "https":
+		return host, 443
+	case "http":
+		return host, 80
+	}
+	return host, 0
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
+
+// fiberHeaderCarrier adapts a Fiber client request to propagation.TextMapCarrier.
+type fiberHeaderCarrier struct {
+	req *client.Request
+}
+
+func (c fiberHeaderCarrier) Get(key string) string {
+	if values := c.req.Header(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c fiberHeaderCarrier) Set(key, value string) {
+	c.req.SetHeader(key, value)
+}
+
+func (c fiberHeaderCarrier) Keys() []string {
+	keys := make([]string, 0)
+	for key := range c.req.Headers() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// fasthttpHeaderCarrier adapts fasthttp request headers to propagation.TextMapCarrier.
+type fasthttpHeaderCarrier struct {
+	h *fasthttp.RequestHeader
+}
+
+func (c fasthttpHeaderCarrier) Get(key string) string {
+	return string(c.h.Peek(key))
+}
+
+func (c fasthttpHeaderCarrier) Set(key, value string) {
+	c.h.Set(key, value)
+}
+
+func (c fasthttpHeaderCarrier) Keys() []string {
+	keys := make([]string, 0)
+	for key := range c.h.All() {
+		keys = append(keys, string(key))
+	}
+	return keys
+}
@@ -0,0 +1,118 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gofiberobservability/pkg/breaker"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestFiberClientRetries(t *testing.T) {
+	srv, hits := statusServer(t, http.StatusServiceUnavailable, http.StatusOK)
+
+	resp, err := NewFiberClient(testOptions()).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	if resp.StatusCode() != http.StatusOK || hits.Load() != 2 {
+		t.Errorf("got status %d after %d attempts, want 200 after 2", resp.StatusCode(), hits.Load())
+	}
+}
+
+func TestFiberClientDoesNotRetryPOST(t *testing.T) {
+	srv, hits := statusServer(t, http.StatusServiceUnavailable)
+
+	resp, err := NewFiberClient(testOptions()).Post(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	if hits.Load() != 1 {
+		t.Errorf("sent %d times, want 1", hits.Load())
+	}
+}
+
+func TestFiberClientHostTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	opts := Options{HostTimeouts: map[string]time.Duration{"127.0.0.1": 50 * time.Millisecond}}
+	start := time.Now()
+	_, err := NewFiberClient(opts).Get(srv.URL)
+
+	if err == nil {
+		t.Fatal("want a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("took %s, want the 50ms host timeout to apply", elapsed)
+	}
+}
+
+func TestFiberClientStopsRetryingAtDeadline(t *testing.T) {
+	srv, hits := statusServer(t, http.StatusServiceUnavailable)
+	opts := Options{MaxRetries: 5, RetryBackoff: 200 * time.Millisecond, RetryMaxBackoff: time.Second}
+
+	resp, err := NewFiberClient(opts).R().SetTimeout(100 * time.Millisecond).Get(srv.URL)
+	if err == nil {
+		resp.Close()
+		t.Fatal("want a timeout error")
+	}
+
+	// The transport outlives the caller's timeout; give a leaked retry loop time to show
+	time.Sleep(time.Second)
+	if hits.Load() != 1 {
+		t.Errorf("server saw %d requests, want 1", hits.Load())
+	}
+}
+
+func TestFiberClientBreaker(t *testing.T) {
+	srv, hits := statusServer(t, http.StatusInternalServerError)
+	c := NewFiberClient(Options{BreakerThreshold: 2, BreakerOpenTimeout: time.Minute})
+
+	for range 2 {
+		resp, err := c.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Close()
+	}
+	if _, err := c.Get(srv.URL); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("err = %v, want breaker.ErrOpen", err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("server saw %d requests, want 2", hits.Load())
+	}
+}
+
+func TestFiberClientInjectsTraceparent(t *testing.T) {
+	var got atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.Store(r.Header.Get("traceparent"))
+	}))
+	defer srv.Close()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+
+	c := NewFiberClient(Options{})
+	req := c.R().SetContext(ctx)
+	resp, err := req.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	assertChildTraceparent(t, got.Load().(string), parent.SpanContext())
+}
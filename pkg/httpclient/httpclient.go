@@ -0,0 +1,48 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"gofiberobservability/pkg/config"
+)
+
+// Options configures the instrumented transport.
+type Options struct {
+	// Timeout bounds each attempt unless HostTimeouts has an entry for the host.
+	Timeout time.Duration
+	// HostTimeouts overrides Timeout per "host" or "host:port".
+	HostTimeouts map[string]time.Duration
+
+	// MaxRetries is the number of resends after the first attempt.
+	// Only idempotent requests (or requests carrying an Idempotency-Key) are retried.
+	MaxRetries int
+	// RetryBackoff is the initial backoff, doubled per attempt up to RetryMaxBackoff, with full jitter.
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+
+	// BreakerThreshold consecutive failures open a host's circuit breaker (0 disables it).
+	BreakerThreshold   int
+	BreakerOpenTimeout time.Duration
+}
+
+// OptionsFromConfig maps the application config onto client options.
+func OptionsFromConfig(cfg *config.Config) Options {
+	return Options{
+		Timeout:            cfg.HTTPClientTimeout,
+		HostTimeouts:       cfg.HTTPClientHostTimeouts,
+		MaxRetries:         cfg.HTTPClientMaxRetries,
+		RetryBackoff:       cfg.HTTPClientRetryBackoff,
+		RetryMaxBackoff:    cfg.HTTPClientRetryMaxBackoff,
+		BreakerThreshold:   cfg.HTTPClientBreakerThreshold,
+		BreakerOpenTimeout: cfg.HTTPClientBreakerOpenTimeout,
+	}
+}
+
+// New returns an *http.Client whose transport traces, measures, retries and
+// circuit-breaks every outbound request.
+func New(opts Options) *http.Client {
+	return &http.Client{
+		Transport: NewTransport(http.DefaultTransport.(*http.Transport).Clone(), opts),
+	}
+}
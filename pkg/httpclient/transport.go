@@ -0,0 +1,295 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gofiberobservability/pkg/breaker"
+	"gofiberobservability/pkg/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "gofiberobservability/httpclient"
+
+// Transport is an http.RoundTripper that creates a client span per attempt,
+// injects the global propagator, records http.client.request.duration and
+// applies per-host timeouts, retries and circuit breaking.
+type Transport struct {
+	base     http.RoundTripper
+	opts     Options
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+
+	mu       sync.Mutex
+	breakers map[string]*breaker.Breaker
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// NewTransport wraps base (http.DefaultTransport when nil).
+func NewTransport(base http.RoundTripper, opts Options) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	duration, _ := metrics.GetMeter().Float64Histogram("http.client.request.duration",
+		metric.WithDescription("Duration of HTTP client requests"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10),
+	)
+
+	return &Transport{
+		base:     base,
+		opts:     opts,
+		tracer:   otel.Tracer(instrumentationName),
+		duration: duration,
+		breakers: make(map[string]*breaker.Breaker),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	br := t.breakerFor(req.URL.Host)
+	if err := br.Allow(); err != nil {
+		return nil, fmt.Errorf("%s: %w", req.URL.Host, err)
+	}
+
+	retryable := t.opts.MaxRetries > 0 && canRetry(req)
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for attempt := 0; ; attempt++ {
+		resp, err = t.attempt(req, attempt)
+		if !retryable || attempt >= t.opts.MaxRetries || !shouldRetry(resp, err) || req.Context().Err() != nil {
+			break
+		}
+
+		// Release the connection of the failed attempt before backing off
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			resp = nil
+		}
+		if err = t.backoff(req.Context(), attempt); err != nil {
+			break
+		}
+	}
+
+	switch {
+	case req.Context().Err() != nil:
+		// The caller gave up (canceled or past its own deadline): says nothing about the host
+		br.Ignore()
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		br.Failure()
+	default:
+		br.Success()
+	}
+
+	return resp, err
+}
+
+// attempt sends a single request with its own span, timeout and metric point.
+func (t *Transport) attempt(req *http.Request, resendCount int) (*http.Response, error) {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.ServerAddress(req.URL.Hostname()),
+	}
+	if port := portOf(req); port > 0 {
+		attrs = append(attrs, semconv.ServerPort(port))
+	}
+
+	spanAttrs := append([]attribute.KeyValue{semconv.URLFull(req.URL.Redacted())}, attrs...)
+	if resendCount > 0 {
+		spanAttrs = append(spanAttrs, semconv.HTTPRequestResendCount(resendCount))
+	}
+
+	ctx, span := t.tracer.Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(spanAttrs...),
+	)
+	defer span.End()
+
+	var cancel context.CancelFunc
+	if timeout := t.timeoutFor(req.URL.Host, req.URL.Hostname()); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	r := req.Clone(ctx)
+	if resendCount > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			if cancel != nil {
+				cancel()
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		r.Body = body
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(r)
+	elapsed := time.Since(start)
+
+	if cancel != nil {
+		if err != nil {
+			cancel()
+		} else {
+			// The deadline must outlive RoundTrip: the caller still reads the body
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		}
+	}
+
+	var errType string
+	switch {
+	case err != nil:
+		errType = errorType(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case resp.StatusCode >= http.StatusBadRequest:
+		errType = strconv.Itoa(resp.StatusCode)
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+
+	var respAttrs []attribute.KeyValue
+	if resp != nil {
+		respAttrs = append(respAttrs, semconv.HTTPResponseStatusCode(resp.StatusCode))
+	}
+	if errType != "" {
+		respAttrs = append(respAttrs, semconv.ErrorTypeKey.String(errType))
+	}
+	span.SetAttributes(respAttrs...)
+
+	t.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(append(attrs, respAttrs...)...))
+
+	return resp, err
+}
+
+// backoff sleeps for an exponentially growing, jittered interval or until ctx is done.
+func (t *Transport) backoff(ctx context.Context, attempt int) error {
+	d := t.opts.RetryBackoff << attempt
+	if d <= 0 || (t.opts.RetryMaxBackoff > 0 && d > t.opts.RetryMaxBackoff) {
+		d = t.opts.RetryMaxBackoff
+	}
+	if d <= 0 {
+		return nil
+	}
+	// Equal jitter: keep half the interval, randomize the rest
+	d = d/2 + rand.N(d/2+1)
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// timeoutFor looks up the attempt timeout by "host:port", then by hostname.
+func (t *Transport) timeoutFor(host, hostname string) time.Duration {
+	if d, ok := t.opts.HostTimeouts[host]; ok {
+		return d
+	}
+	if d, ok := t.opts.HostTimeouts[hostname]; ok {
+		return d
+	}
+	return t.opts.Timeout
+}
+
+func (t *Transport) breakerFor(host string) *breaker.Breaker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	br, ok := t.breakers[host]
+	if !ok {
+		br = breaker.New(host, t.opts.BreakerThreshold, t.opts.BreakerOpenTimeout)
+		t.breakers[host] = br
+	}
+	return br
+}
+
+// canRetry reports whether the request is safe to send again.
+func canRetry(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	return isIdempotent(req.Method, req.Header.Get("Idempotency-Key"))
+}
+
+func isIdempotent(method, idempotencyKey string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return idempotencyKey != ""
+}
+
+// shouldRetry reports whether the outcome of an attempt is transient.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return isTransientStatus(resp.StatusCode)
+}
+
+func isTransientStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func errorType(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+func portOf(req *http.Request) int {
+	if p := req.URL.Port(); p != "" {
+		port, _ := strconv.Atoi(p)
+		return port
+	}
+	switch req.URL.Scheme {
+	case "https":
+		return 443
+	case "http":
+		return 80
+	}
+	return 0
+}
+
+// cancelOnClose releases the per-attempt timeout once the caller is done with the body.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -8,6 +8,7 @@ import (
 
 	"go.opentelemetry.io/contrib/bridges/otelzap"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
 	"go.opentelemetry.io/otel/log/global"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
@@ -21,6 +22,7 @@ import (
 var (
 	loggerProvider *sdklog.LoggerProvider
 	zapLogger      *zap.Logger
+	baggageKeys    []string
 )
 
 // InitLogger initializes Zap logger with OpenTelemetry OTLP gRPC exporter
@@ -66,6 +68,9 @@ func InitLogger(cfg *config.Config) error {
 		sdklog.WithMaxQueueSize(cfg.BatchMaxQueueSize),
 	)
 
+	// Baggage members copied onto request-scoped loggers
+	baggageKeys = cfg.BaggageLogKeys
+
 	// Create logger provider
 	loggerProvider = sdklog.NewLoggerProvider(
 		sdklog.WithResource(res),
@@ -124,7 +129,7 @@ func GetLogger() *zap.Logger {
 	return zapLogger
 }
 
-// GetLoggerWithTraceContext returns logger with trace context fields
+// GetLoggerWithTraceContext returns logger with trace context and allowlisted baggage fields
 func GetLoggerWithTraceContext(ctx context.Context) *zap.Logger {
 	logger := GetLogger()
 
@@ -137,6 +142,15 @@ func GetLoggerWithTraceContext(ctx context.Context) *zap.Logger {
 		)
 	}
 
+	// Extract allowlisted baggage members (e.g. tenant.id set by the gateway)
+	if bag := baggage.FromContext(ctx); bag.Len() > 0 {
+		for _, key := range baggageKeys {
+			if m := bag.Member(key); m.Key() != "" {
+				logger = logger.With(zap.String(key, m.Value()))
+			}
+		}
+	}
+
 	return logger
 }
 
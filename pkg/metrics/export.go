@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"fmt"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// instrumentKinds maps the names accepted in OTEL_METRICS_TEMPORALITY_OVERRIDES
+// to SDK instrument kinds.
+var instrumentKinds = map[string]sdkmetric.InstrumentKind{
+	"counter":                    sdkmetric.InstrumentKindCounter,
+	"up_down_counter":            sdkmetric.InstrumentKindUpDownCounter,
+	"histogram":                  sdkmetric.InstrumentKindHistogram,
+	"gauge":                      sdkmetric.InstrumentKindGauge,
+	"observable_counter":         sdkmetric.InstrumentKindObservableCounter,
+	"observable_up_down_counter": sdkmetric.InstrumentKindObservableUpDownCounter,
+	"observable_gauge":           sdkmetric.InstrumentKindObservableGauge,
+}
+
+// temporalitySelector builds the OTLP temporality selector from a preference
+// (cumulative, delta or lowmemory, as defined by the OTLP exporter spec) and
+// per-kind overrides. Up-down counters stay cumulative under delta because
+// their deltas cannot be summed back into a meaningful value.
+func temporalitySelector(preference string, overrides map[string]string) (sdkmetric.TemporalitySelector, error) {
+	var base sdkmetric.TemporalitySelector
+	switch preference {
+	case "", "cumulative":
+		base = sdkmetric.DefaultTemporalitySelector
+	case "delta":
+		base = func(k sdkmetric.InstrumentKind) metricdata.Temporality {
+			switch k {
+			case sdkmetric.InstrumentKindCounter,
+				sdkmetric.InstrumentKindObservableCounter,
+				sdkmetric.InstrumentKindHistogram:
+				return metricdata.DeltaTemporality
+			}
+			return metricdata.CumulativeTemporality
+		}
+	case "lowmemory":
+		// Synchronous instruments only, so async counters are not re-diffed every cycle
+		base = func(k sdkmetric.InstrumentKind) metricdata.Temporality {
+			switch k {
+			case sdkmetric.InstrumentKindCounter, sdkmetric.InstrumentKindHistogram:
+				return metricdata.DeltaTemporality
+			}
+			return metricdata.CumulativeTemporality
+		}
+	default:
+		return nil, fmt.Errorf("unknown metrics temporality %q (want cumulative, delta or lowmemory)", preference)
+	}
+
+	if len(overrides) == 0 {
+		return base, nil
+	}
+
+	perKind := make(map[sdkmetric.InstrumentKind]metricdata.Temporality, len(overrides))
+	for name, value := range overrides {
+		kind, ok := instrumentKinds[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown instrument kind %q in metrics temporality overrides", name)
+		}
+		switch value {
+		case "cumulative":
+			perKind[kind] = metricdata.CumulativeTemporality
+		case "delta":
+			perKind[kind] = metricdata.DeltaTemporality
+		default:
+			return nil, fmt.Errorf("unknown temporality %q for %s (want cumulative or delta)", value, name)
+		}
+	}
+
+	return func(k sdkmetric.InstrumentKind) metricdata.Temporality {
+		if t, ok := perKind[k]; ok {
+			return t
+		}
+		return base(k)
+	}, nil
+}
+
+// exemplarFilter maps the configured filter name to an SDK exemplar filter.
+// The spec names (always_on, always_off) are accepted as aliases.
+func exemplarFilter(name string) (exemplar.Filter, error) {
+	switch name {
+	case "", "trace_based":
+		return exemplar.TraceBasedFilter, nil
+	case "always", "always_on":
+		return exemplar.AlwaysOnFilter, nil
+	case "off", "always_off":
+		return exemplar.AlwaysOffFilter, nil
+	}
+	return nil, fmt.Errorf("unknown metrics exemplar filter %q (want always, trace_based or off)", name)
+}
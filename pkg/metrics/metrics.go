@@ -3,17 +3,19 @@ package metrics
 import (
 	"context"
 	"fmt"
-	"time"
+	"net/http"
 
 	"gofiberobservability/pkg/config"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
-	otplexemplar "go.opentelemetry.io/otel/sdk/metric/exemplar"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.uber.org/zap"
@@ -22,19 +24,65 @@ import (
 var (
 	meterProvider *sdkmetric.MeterProvider
 	meter         metric.Meter
+	promHandler   http.Handler
 )
 
-// InitMetrics initializes the OpenTelemetry Metrics SDK with OTLP exporter
+// InitMetrics initializes the OpenTelemetry Metrics SDK with an OTLP push exporter
+// and/or a Prometheus pull reader on the same MeterProvider.
 func InitMetrics(cfg *config.Config, log *zap.Logger) error {
 	ctx := context.Background()
 
-	// Create OTLP exporter
-	exporter, err := otlpmetricgrpc.New(ctx,
-		otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint),
-		otlpmetricgrpc.WithInsecure(),
-	)
+	// Views from config: bucket boundaries, exponential histograms, attribute filters, renames
+	viewConfigs, err := LoadViews(cfg.MetricsViewsFile)
+	if err != nil {
+		return err
+	}
+	views, err := buildViews(viewConfigs)
+	if err != nil {
+		return err
+	}
+
+	// Export tuning: temporality applies to OTLP only, Prometheus is always cumulative
+	temporality, err := temporalitySelector(cfg.MetricsTemporality, cfg.MetricsTemporalityOverrides)
+	if err != nil {
+		return err
+	}
+	filter, err := exemplarFilter(cfg.MetricsExemplarFilter)
 	if err != nil {
-		return fmt.Errorf("failed to create metrics exporter: %w", err)
+		return err
+	}
+
+	var readers []sdkmetric.Option
+
+	// OTLP push to the collector
+	if cfg.MetricsOTLPEnabled {
+		exporter, err := otlpmetricgrpc.New(ctx,
+			otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint),
+			otlpmetricgrpc.WithInsecure(),
+			otlpmetricgrpc.WithTemporalitySelector(temporality),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create metrics exporter: %w", err)
+		}
+		readers = append(readers, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter,
+			sdkmetric.WithInterval(cfg.MetricsExportInterval),
+			sdkmetric.WithTimeout(cfg.MetricsExportTimeout),
+		)))
+	}
+
+	// Prometheus pull, for clusters that scrape pods directly
+	if cfg.MetricsPrometheusEnabled {
+		registry := prometheus.NewRegistry()
+		promExporter, err := otelprom.New(otelprom.WithRegisterer(registry))
+		if err != nil {
+			return fmt.Errorf("failed to create prometheus exporter: %w", err)
+		}
+		readers = append(readers, sdkmetric.WithReader(promExporter))
+
+		// OpenMetrics is required for exemplars to be exposed
+		promHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		})
 	}
 
 	// Create resource
@@ -53,13 +101,15 @@ func InitMetrics(cfg *config.Config, log *zap.Logger) error {
 		return fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	// Create MeterProvider with periodic exporting and trace-based exemplars
-	meterProvider = sdkmetric.NewMeterProvider(
+	// Create MeterProvider with the configured readers and exemplar filter.
+	// Series beyond the cardinality limit are folded into a single
+	// otel.metric.overflow=true data point per instrument.
+	meterProvider = sdkmetric.NewMeterProvider(append(readers,
 		sdkmetric.WithResource(res),
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter,
-			sdkmetric.WithInterval(15*time.Second))),
-		sdkmetric.WithExemplarFilter(otplexemplar.TraceBasedFilter),
-	)
+		sdkmetric.WithView(views...),
+		sdkmetric.WithExemplarFilter(filter),
+		sdkmetric.WithCardinalityLimit(cfg.MetricsCardinalityLimit),
+	)...)
 
 	// Set global MeterProvider
 	otel.SetMeterProvider(meterProvider)
@@ -75,6 +125,13 @@ func InitMetrics(cfg *config.Config, log *zap.Logger) error {
 	log.Info("OpenTelemetry metrics initialized",
 		zap.String("otlp_endpoint", cfg.OTLPEndpoint),
 		zap.String("service", cfg.ServiceName),
+		zap.Bool("otlp_push", cfg.MetricsOTLPEnabled),
+		zap.Bool("prometheus", cfg.MetricsPrometheusEnabled),
+		zap.Int("views", len(views)),
+		zap.Duration("export_interval", cfg.MetricsExportInterval),
+		zap.String("temporality", cfg.MetricsTemporality),
+		zap.String("exemplar_filter", cfg.MetricsExemplarFilter),
+		zap.Int("cardinality_limit", cfg.MetricsCardinalityLimit),
 	)
 
 	return nil
@@ -82,9 +139,19 @@ func InitMetrics(cfg *config.Config, log *zap.Logger) error {
 
 // GetMeter returns the initialized Meter
 func GetMeter() metric.Meter {
+	if meter == nil {
+		// Fallback to the global provider (no-op until InitMetrics runs)
+		return otel.Meter("gofiberobservability")
+	}
 	return meter
 }
 
+// PrometheusHandler returns the OpenMetrics scrape handler, or nil when the
+// Prometheus reader is disabled.
+func PrometheusHandler() http.Handler {
+	return promHandler
+}
+
 // Shutdown flushes and stops the MeterProvider
 func Shutdown(ctx context.Context, log *zap.Logger) {
 	if meterProvider == nil {
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// ViewConfig customizes the stream produced by the instruments it matches.
+type ViewConfig struct {
+	// Instrument is the instrument name to match; "*" and "?" wildcards are allowed.
+	Instrument string `json:"instrument"`
+	// Rename sets a new metric name (only valid for a non-wildcard Instrument).
+	Rename string `json:"rename,omitempty"`
+	// Description overrides the instrument description.
+	Description string `json:"description,omitempty"`
+
+	// Aggregation is one of "explicit", "exponential", "sum", "last_value", "drop" or "" (default).
+	Aggregation string `json:"aggregation,omitempty"`
+	// Buckets are the explicit histogram boundaries.
+	Buckets []float64 `json:"buckets,omitempty"`
+	// MaxSize and MaxScale tune base-2 exponential histograms (defaults 160 / 20).
+	MaxSize  int32 `json:"max_size,omitempty"`
+	MaxScale int32 `json:"max_scale,omitempty"`
+
+	// AttributeKeys keeps only these attributes; DropAttributeKeys removes these.
+	AttributeKeys     []string `json:"attribute_keys,omitempty"`
+	DropAttributeKeys []string `json:"drop_attribute_keys,omitempty"`
+}
+
+// LoadViews reads a JSON array of ViewConfig from path. An empty path yields no views.
+func LoadViews(path string) ([]ViewConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metric views: %w", err)
+	}
+
+	var views []ViewConfig
+	if err := json.Unmarshal(data, &views); err != nil {
+		return nil, fmt.Errorf("failed to parse metric views %s: %w", path, err)
+	}
+	return views, nil
+}
+
+// buildViews converts view configs into SDK views, validating them first since
+// sdkmetric.NewView only logs invalid definitions.
+func buildViews(configs []ViewConfig) ([]sdkmetric.View, error) {
+	views := make([]sdkmetric.View, 0, len(configs))
+
+	for i, vc := range configs {
+		if vc.Instrument == "" {
+			return nil, fmt.Errorf("metric view %d: instrument is required", i)
+		}
+		if vc.Rename != "" && strings.ContainsAny(vc.Instrument, "*?") {
+			return nil, fmt.Errorf("metric view %q: rename requires an exact instrument name", vc.Instrument)
+		}
+		if len(vc.AttributeKeys) > 0 && len(vc.DropAttributeKeys) > 0 {
+			return nil, fmt.Errorf("metric view %q: attribute_keys and drop_attribute_keys are mutually exclusive", vc.Instrument)
+		}
+
+		stream := sdkmetric.Stream{
+			Name:        vc.Rename,
+			Description: vc.Description,
+		}
+
+		switch vc.Aggregation {
+		case "":
+		case "explicit":
+			if len(vc.Buckets) == 0 || !sort.Float64sAreSorted(vc.Buckets) {
+				return nil, fmt.Errorf("metric view %q: explicit aggregation requires ascending buckets", vc.Instrument)
+			}
+			stream.Aggregation = sdkmetric.AggregationExplicitBucketHistogram{Boundaries: vc.Buckets}
+		case "exponential":
+			agg := sdkmetric.AggregationBase2ExponentialHistogram{MaxSize: 160, MaxScale: 20}
+			if vc.MaxSize > 0 {
+				agg.MaxSize = vc.MaxSize
+			}
+			if vc.MaxScale > 0 {
+				agg.MaxScale = vc.MaxScale
+			}
+			stream.Aggregation = agg
+		case "sum":
+			stream.Aggregation = sdkmetric.AggregationSum{}
+		case "last_value":
+			stream.Aggregation = sdkmetric.AggregationLastValue{}
+		case "drop":
+			stream.Aggregation = sdkmetric.AggregationDrop{}
+		default:
+			return nil, fmt.Errorf("metric view %q: unknown aggregation %q", vc.Instrument, vc.Aggregation)
+		}
+
+		switch {
+		case len(vc.AttributeKeys) > 0:
+			stream.AttributeFilter = attribute.NewAllowKeysFilter(toKeys(vc.AttributeKeys)...)
+		case len(vc.DropAttributeKeys) > 0:
+			stream.AttributeFilter = attribute.NewDenyKeysFilter(toKeys(vc.DropAttributeKeys)...)
+		}
+
+		views = append(views, sdkmetric.NewView(sdkmetric.Instrument{Name: vc.Instrument}, stream))
+	}
+
+	return views, nil
+}
+
+func toKeys(names []string) []attribute.Key {
+	keys := make([]attribute.Key, len(names))
+	for i, n := range names {
+		keys[i] = attribute.Key(n)
+	}
+	return keys
+}
@@ -0,0 +1,163 @@
+package slo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Objective is a request-based SLO: Target of the requests matching Method and
+// Route must succeed (status < 500) and, when Latency is set, complete within
+// Latency, measured over Window.
+type Objective struct {
+	// Name identifies the objective in metrics (slo.name) and /debug/slo.
+	Name string `json:"name"`
+	// Method is the HTTP method to match; empty matches every method.
+	Method string `json:"method,omitempty"`
+	// Route is the Fiber route template, e.g. "/api/users/:id".
+	Route string `json:"route"`
+	// Target is the good-event ratio, e.g. 0.999.
+	Target float64 `json:"target"`
+	// Latency is the threshold a good request must meet; zero means availability only.
+	Latency Duration `json:"latency,omitempty"`
+	// Window is the compliance period the error budget is spent over (default 30d).
+	Window Duration `json:"window,omitempty"`
+}
+
+// ErrorBudget is the allowed bad-event ratio (1 - Target).
+func (o Objective) ErrorBudget() float64 {
+	return 1 - o.Target
+}
+
+// BurnRateAlert is one multi-window burn-rate condition: it fires when both the
+// long and the short window burn faster than the rate that would consume
+// BudgetSpent of the error budget within the long window.
+type BurnRateAlert struct {
+	Severity    string
+	LongWindow  time.Duration
+	ShortWindow time.Duration
+	BudgetSpent float64
+}
+
+// Threshold is the burn rate that triggers the alert for an objective window.
+// For a 30d window these are the usual 14.4 / 6 / 3 / 1.
+func (a BurnRateAlert) Threshold(window time.Duration) float64 {
+	return a.BudgetSpent * float64(window) / float64(a.LongWindow)
+}
+
+// BurnRateAlerts are the multi-window, multi-burn-rate alerts from the Google SRE workbook.
+var BurnRateAlerts = []BurnRateAlert{
+	{Severity: "page", LongWindow: time.Hour, ShortWindow: 5 * time.Minute, BudgetSpent: 0.02},
+	{Severity: "page", LongWindow: 6 * time.Hour, ShortWindow: 30 * time.Minute, BudgetSpent: 0.05},
+	{Severity: "ticket", LongWindow: 24 * time.Hour, ShortWindow: 2 * time.Hour, BudgetSpent: 0.10},
+	{Severity: "ticket", LongWindow: 72 * time.Hour, ShortWindow: 6 * time.Hour, BudgetSpent: 0.10},
+}
+
+// BurnRateWindows are the distinct windows used by BurnRateAlerts, shortest first.
+var BurnRateWindows = []time.Duration{
+	5 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour, 6 * time.Hour, 24 * time.Hour, 72 * time.Hour,
+}
+
+const defaultWindow = 30 * 24 * time.Hour
+
+// LoadObjectives reads a JSON array of Objective from path. An empty path yields no objectives.
+func LoadObjectives(path string) ([]Objective, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SLO objectives: %w", err)
+	}
+
+	var objectives []Objective
+	if err := json.Unmarshal(data, &objectives); err != nil {
+		return nil, fmt.Errorf("failed to parse SLO objectives %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(objectives))
+	for i := range objectives {
+		o := &objectives[i]
+		if o.Window.Duration == 0 {
+			o.Window.Duration = defaultWindow
+		}
+		o.Method = strings.ToUpper(o.Method)
+
+		switch {
+		case o.Name == "":
+			return nil, fmt.Errorf("SLO objective %d: name is required", i)
+		case seen[o.Name]:
+			return nil, fmt.Errorf("SLO objective %q: duplicate name", o.Name)
+		case o.Route == "":
+			return nil, fmt.Errorf("SLO objective %q: route is required", o.Name)
+		case o.Target <= 0 || o.Target >= 1:
+			return nil, fmt.Errorf("SLO objective %q: target must be between 0 and 1 exclusive", o.Name)
+		case o.Latency.Duration < 0:
+			return nil, fmt.Errorf("SLO objective %q: latency must not be negative", o.Name)
+		case o.Window.Duration < BurnRateWindows[len(BurnRateWindows)-1]:
+			return nil, fmt.Errorf("SLO objective %q: window must be at least 3d", o.Name)
+		}
+		seen[o.Name] = true
+	}
+	return objectives, nil
+}
+
+// Duration is a time.Duration that (un)marshals as a string and also accepts a "d" (day) suffix.
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON parses "200ms", "1h30m" or "30d".
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+	parsed, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalJSON writes the duration in the form ParseDuration accepts.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(FormatDuration(d.Duration))
+}
+
+// ParseDuration is time.ParseDuration plus whole days ("30d").
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// FormatDuration renders d compactly ("5m", "6h", "3d", "200ms"), which also
+// suits Prometheus range selectors.
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "0s"
+	case d%(24*time.Hour) == 0:
+		return strconv.FormatInt(int64(d/(24*time.Hour)), 10) + "d"
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	case d%time.Second == 0:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	case d%time.Millisecond == 0:
+		return strconv.FormatInt(int64(d/time.Millisecond), 10) + "ms"
+	}
+	return d.String()
+}
@@ -0,0 +1,82 @@
+package slo
+
+import (
+	"context"
+
+	"gofiberobservability/pkg/config"
+	"gofiberobservability/pkg/metrics"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+var tracker *Tracker
+
+// InitSLO loads the objectives from cfg.SLOObjectivesFile, creates the tracker
+// fed by the HTTP middleware and exports burn rates and remaining error budget
+// as gauges. Without objectives GetTracker returns nil and nothing is recorded.
+func InitSLO(cfg *config.Config, log *zap.Logger) error {
+	objectives, err := LoadObjectives(cfg.SLOObjectivesFile)
+	if err != nil {
+		return err
+	}
+	if len(objectives) == 0 {
+		return nil
+	}
+
+	meter := metrics.GetMeter()
+	t, err := NewTracker(objectives, meter)
+	if err != nil {
+		return err
+	}
+	if err := registerMetrics(t, meter); err != nil {
+		return err
+	}
+	tracker = t
+
+	log.Info("SLO tracking initialized", zap.Int("objectives", len(objectives)))
+	return nil
+}
+
+// GetTracker returns the initialized tracker, or nil when no objectives are declared.
+func GetTracker() *Tracker {
+	return tracker
+}
+
+func registerMetrics(t *Tracker, meter metric.Meter) error {
+	objective, err := meter.Float64ObservableGauge(ObjectiveMetric,
+		metric.WithDescription("Target good-event ratio of the SLO"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+	burnRate, err := meter.Float64ObservableGauge("slo.burn_rate",
+		metric.WithDescription("Error ratio over slo.window divided by the error budget (1 = spending exactly on budget)"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+	budgetRemaining, err := meter.Float64ObservableGauge("slo.error_budget.remaining",
+		metric.WithDescription("Unspent share of the error budget over the SLO window"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, s := range t.Snapshot() {
+			name := attribute.String("slo.name", s.Name)
+			o.ObserveFloat64(objective, s.Target, metric.WithAttributes(name))
+			o.ObserveFloat64(budgetRemaining, s.ErrorBudgetRemaining, metric.WithAttributes(name))
+			for window, rate := range s.BurnRates {
+				o.ObserveFloat64(burnRate, rate, metric.WithAttributes(name, attribute.String("slo.window", window)))
+			}
+		}
+		return nil
+	}, objective, burnRate, budgetRemaining)
+	return err
+}
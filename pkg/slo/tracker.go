@@ -0,0 +1,215 @@
+package slo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Instrument names, shared with cmd/rulesgen.
+const (
+	EventsMetric    = "slo.events"
+	ObjectiveMetric = "slo.objective"
+)
+
+// Tracker classifies requests against the declared objectives and keeps
+// per-objective good/total counts in time-bucketed rings. The counts are local
+// to this process and restart with it; fleet-wide burn rates come from the
+// slo.events counter (see cmd/rulesgen).
+type Tracker struct {
+	objectives []*tracked
+	byRoute    map[string][]*tracked
+
+	events metric.Int64Counter
+}
+
+type tracked struct {
+	Objective
+
+	mu     sync.Mutex
+	burn   *ring // fine-grained, covers the longest burn-rate window
+	budget *ring // coarse, covers the whole objective window
+}
+
+// NewTracker builds a tracker for objectives. Good and bad events are also
+// counted on slo.events through meter.
+func NewTracker(objectives []Objective, meter metric.Meter) (*Tracker, error) {
+	events, err := meter.Int64Counter(EventsMetric,
+		metric.WithDescription("Requests classified against an SLO, by outcome (good, bad)"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tracker{
+		byRoute: make(map[string][]*tracked),
+		events:  events,
+	}
+	longest := BurnRateWindows[len(BurnRateWindows)-1]
+	for _, o := range objectives {
+		budgetRes := max(time.Minute, o.Window.Duration/720)
+		tr := &tracked{
+			Objective: o,
+			burn:      newRing(time.Minute, longest),
+			budget:    newRing(budgetRes, o.Window.Duration),
+		}
+		t.objectives = append(t.objectives, tr)
+		t.byRoute[o.Route] = append(t.byRoute[o.Route], tr)
+	}
+	return t, nil
+}
+
+// Observe classifies one finished request against every objective matching
+// method and route. It is safe to call on a nil Tracker.
+func (t *Tracker) Observe(ctx context.Context, method, route string, status int, duration time.Duration) {
+	if t == nil || route == "" {
+		return
+	}
+
+	now := time.Now()
+	for _, tr := range t.byRoute[route] {
+		if tr.Method != "" && tr.Method != method {
+			continue
+		}
+
+		good := status < 500 && (tr.Latency.Duration == 0 || duration <= tr.Latency.Duration)
+
+		tr.mu.Lock()
+		tr.burn.add(now, good)
+		tr.budget.add(now, good)
+		tr.mu.Unlock()
+
+		outcome := "good"
+		if !good {
+			outcome = "bad"
+		}
+		t.events.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("slo.name", tr.Name),
+			attribute.String("outcome", outcome),
+		))
+	}
+}
+
+// Status is the point-in-time state of one objective, as served on /debug/slo.
+type Status struct {
+	Objective
+
+	// Good and Total are the events seen in this process over the objective window.
+	Good  int64 `json:"good"`
+	Total int64 `json:"total"`
+	// ErrorBudgetRemaining is the unspent share of the error budget (negative when overspent).
+	ErrorBudgetRemaining float64 `json:"error_budget_remaining"`
+	// BurnRates maps window ("5m", "1h", ...) to the error ratio divided by the error budget.
+	BurnRates map[string]float64 `json:"burn_rates"`
+	Alerts    []AlertStatus      `json:"alerts"`
+}
+
+// AlertStatus reports whether a BurnRateAlert currently fires for the objective.
+type AlertStatus struct {
+	Severity    string  `json:"severity"`
+	LongWindow  string  `json:"long_window"`
+	ShortWindow string  `json:"short_window"`
+	Threshold   float64 `json:"threshold"`
+	Firing      bool    `json:"firing"`
+}
+
+// Snapshot computes the current status of every objective.
+func (t *Tracker) Snapshot() []Status {
+	if t == nil {
+		return nil
+	}
+
+	now := time.Now()
+	statuses := make([]Status, 0, len(t.objectives))
+	for _, tr := range t.objectives {
+		s := Status{Objective: tr.Objective, BurnRates: make(map[string]float64, len(BurnRateWindows))}
+		budget := tr.ErrorBudget()
+
+		tr.mu.Lock()
+		s.Good, s.Total = tr.budget.sum(now, tr.Window.Duration)
+		burn := make(map[time.Duration]float64, len(BurnRateWindows))
+		for _, w := range BurnRateWindows {
+			good, total := tr.burn.sum(now, w)
+			burn[w] = burnRate(good, total, budget)
+		}
+		tr.mu.Unlock()
+
+		s.ErrorBudgetRemaining = 1
+		if s.Total > 0 {
+			s.ErrorBudgetRemaining = 1 - float64(s.Total-s.Good)/(float64(s.Total)*budget)
+		}
+		for w, rate := range burn {
+			s.BurnRates[FormatDuration(w)] = rate
+		}
+		for _, a := range BurnRateAlerts {
+			threshold := a.Threshold(tr.Window.Duration)
+			s.Alerts = append(s.Alerts, AlertStatus{
+				Severity:    a.Severity,
+				LongWindow:  FormatDuration(a.LongWindow),
+				ShortWindow: FormatDuration(a.ShortWindow),
+				Threshold:   threshold,
+				Firing:      burn[a.LongWindow] > threshold && burn[a.ShortWindow] > threshold,
+			})
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+func burnRate(good, total int64, budget float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(total-good) / float64(total) / budget
+}
+
+// ring counts events in fixed-width time buckets covering span. Each slot
+// remembers the bucket index it holds, so stale slots are skipped instead of
+// having to be cleared on a timer.
+type ring struct {
+	res   time.Duration
+	slots []slot
+}
+
+type slot struct {
+	bucket      int64
+	good, total int64
+}
+
+func newRing(res, span time.Duration) *ring {
+	n := int(span / res)
+	if span%res != 0 {
+		n++
+	}
+	return &ring{res: res, slots: make([]slot, n)}
+}
+
+func (r *ring) add(now time.Time, good bool) {
+	b := now.UnixNano() / int64(r.res)
+	s := &r.slots[b%int64(len(r.slots))]
+	if s.bucket != b {
+		*s = slot{bucket: b}
+	}
+	s.total++
+	if good {
+		s.good++
+	}
+}
+
+// sum adds up the buckets overlapping the last span, including the current one.
+func (r *ring) sum(now time.Time, span time.Duration) (good, total int64) {
+	b := now.UnixNano() / int64(r.res)
+	n := min(int64(span/r.res), int64(len(r.slots)))
+	for i := int64(0); i < n; i++ {
+		s := r.slots[(b-i)%int64(len(r.slots))]
+		if s.bucket == b-i {
+			good += s.good
+			total += s.total
+		}
+	}
+	return good, total
+}
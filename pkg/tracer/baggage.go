@@ -0,0 +1,40 @@
+package tracer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// baggageSpanProcessor copies allowlisted W3C baggage members onto every span at start.
+// Only allowlisted keys are copied so callers can't inject arbitrary attributes.
+type baggageSpanProcessor struct {
+	keys []string
+}
+
+var _ sdktrace.SpanProcessor = (*baggageSpanProcessor)(nil)
+
+func newBaggageSpanProcessor(keys []string) *baggageSpanProcessor {
+	return &baggageSpanProcessor{keys: keys}
+}
+
+func (p *baggageSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	bag := baggage.FromContext(parent)
+	if bag.Len() == 0 {
+		return
+	}
+
+	for _, key := range p.keys {
+		if m := bag.Member(key); m.Key() != "" {
+			s.SetAttributes(attribute.String(key, m.Value()))
+		}
+	}
+}
+
+func (p *baggageSpanProcessor) OnEnd(sdktrace.ReadOnlySpan) {}
+
+func (p *baggageSpanProcessor) Shutdown(context.Context) error { return nil }
+
+func (p *baggageSpanProcessor) ForceFlush(context.Context) error { return nil }
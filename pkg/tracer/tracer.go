@@ -73,11 +73,19 @@ func InitTracer(cfg *config.Config, logger *zap.Logger) error {
 		sdktrace.TraceIDRatioBased(cfg.TraceSampleRate),
 	)
 
-	tracerProvider = sdktrace.NewTracerProvider(
+	providerOptions := []sdktrace.TracerProviderOption{
 		sdktrace.WithSampler(sampler),
 		sdktrace.WithResource(res),
-		sdktrace.WithSpanProcessor(batchProcessor),
-	)
+	}
+
+	// Promote allowlisted baggage (e.g. tenant.id from the gateway) to span attributes.
+	// Registered before the batch processor so attributes are set before export.
+	if len(cfg.BaggageSpanKeys) > 0 {
+		providerOptions = append(providerOptions, sdktrace.WithSpanProcessor(newBaggageSpanProcessor(cfg.BaggageSpanKeys)))
+	}
+	providerOptions = append(providerOptions, sdktrace.WithSpanProcessor(batchProcessor))
+
+	tracerProvider = sdktrace.NewTracerProvider(providerOptions...)
 
 	// Set global tracer provider
 	otel.SetTracerProvider(tracerProvider)
@@ -96,6 +104,7 @@ func InitTracer(cfg *config.Config, logger *zap.Logger) error {
 		zap.String("environment", cfg.ServiceEnvironment),
 		zap.String("otlp_endpoint", cfg.OTLPEndpoint),
 		zap.Float64("sample_rate", cfg.TraceSampleRate),
+		zap.Strings("baggage_span_keys", cfg.BaggageSpanKeys),
 	)
 
 	return nil